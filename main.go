@@ -6,82 +6,263 @@ import (
 	"aws-resource-discovery/pkg/interfaces"
 	"aws-resource-discovery/pkg/logger"
 	"aws-resource-discovery/pkg/managers"
+	"aws-resource-discovery/pkg/preflight"
 	"aws-resource-discovery/pkg/scanner"
+	"aws-resource-discovery/pkg/utils"
 	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	aws_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	aws_trail "github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
+// preflightCommand checks the permissions of the scanning role instead of scanning.
+const preflightCommand = "preflight"
+
 func main() {
 	ctx := context.Background()
+	preflightOnly := len(os.Args) > 1 && os.Args[1] == preflightCommand
+	if preflightOnly {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
 	userConfig := parseFlags()
+	if err := userConfig.Validate(); err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+	if preflightOnly {
+		runPreflight(ctx, userConfig)
+		return
+	}
 
 	// Setup CSV Logger
-	csvLogger, err := logger.NewCSVLogger("aws-resource-discovery.csv")
+	csvLogger, err := logger.NewCSVLogger("aws-resource-discovery.csv", scanner.ReportHeader...)
 	if err != nil {
 		log.Fatalf("Failed to initialize CSV logger: %v", err)
 	}
 	defer csvLogger.Close()
 
-	// Load the AWS SDK configuration
-	cfg, err := aws_config.LoadDefaultConfig(ctx)
+	// Setup the inventory CSV Logger
+	var inventoryLogger interfaces.Logger
+	if userConfig.Inventory {
+		inventoryLogger, err = logger.NewCSVLogger("aws-resource-discovery-inventory.csv")
+		if err != nil {
+			log.Fatalf("Failed to initialize inventory CSV logger: %v", err)
+		}
+		defer inventoryLogger.Close()
+	}
+
+	// Setup the capacity CSV Logger of the Auto Scaling groups
+	capacityLogger, err := logger.NewCSVLogger("aws-resource-discovery-capacity.csv", scanner.CapacityHeader...)
 	if err != nil {
-		log.Fatalf("Failed to load AWS configuration: %v", err)
+		log.Fatalf("Failed to initialize capacity CSV logger: %v", err)
 	}
+	defer capacityLogger.Close()
 
-	// Create STS Client
-	stsClient := sts.NewFromConfig(cfg)
-	credsManager := managers.NewCredentialsManager(userConfig.RoleName, stsClient)
-	sessionManager := managers.NewSessionManager(stsClient)
+	// Every AWS client retries with the same configuration, and its throttles
+	// and retries are reported after the totals
+	retries := utils.NewRetries(userConfig.MaxAttempts)
 
-	// Create EC2 Client
-	ec2Client := ec2.NewFromConfig(cfg)
+	// Every target is scanned in turn into the same reports, and accounts
+	// found in more than one target are only scanned in the first
+	// A target that fails is reported and the others are still scanned
+	targets := scanTargets(userConfig)
+	scanned := map[string]string{}
+	names := []string{}
+	targetTotals := map[string]*interfaces.ResourceTotals{}
+	combined := interfaces.ResourceTotals{}
+	ouTotals := map[string]*interfaces.ResourceTotals{}
+	accountTotals := map[string]*interfaces.ResourceTotals{}
+	failed := map[string]error{}
+	var exceeded []interfaces.Discrepancy
+	for _, target := range targets {
+		targetConfig := target.Apply(userConfig)
+		if err := targetConfig.Validate(); err != nil {
+			log.Printf("Invalid target %s: %v", target.Name, err)
+			failed[target.Name] = err
+			continue
+		}
+		if target.Name != "" {
+			fmt.Printf("Scanning target %s.\n", target.Name)
+		}
+		scanResult, targetExceeded, err := scanTarget(ctx, targetConfig, scanned, retries, csvLogger, inventoryLogger, capacityLogger)
+		if err != nil {
+			log.Printf("Failed to scan target %s: %v", target.Name, err)
+			failed[target.Name] = err
+			continue
+		}
+		names = append(names, target.Name)
+		targetTotals[target.Name] = &scanResult.Totals
+		combined.Add(scanResult.Totals)
+		// Organizational unit paths of different organizations can collide, so
+		// they are named after their target when there are several
+		ouPrefix := ""
+		if len(targets) > 1 {
+			ouPrefix = target.Name + ":"
+		}
+		utils.AddSubtotals(ouTotals, ouPrefix, scanResult.OUTotals)
+		utils.AddSubtotals(accountTotals, "", scanResult.AccountTotals)
+		exceeded = append(exceeded, targetExceeded...)
+	}
 
-	// Create RegionsManager with EC2 Client
-	regionsManager := managers.NewRegionManager(ec2Client)
+	jsonReport := utils.JSONReport{
+		GroupByTag:          userConfig.GroupByTag,
+		Totals:              combined,
+		OrganizationalUnits: ouTotals,
+		Accounts:            accountTotals,
+	}
+	if len(targets) > 1 {
+		jsonReport.Targets = targetTotals
+	}
+	if err := utils.WriteJSONReport("aws-resource-discovery.json", jsonReport); err != nil {
+		log.Printf("Failed to write the JSON report: %v", err)
+	}
 
-	// Create Organizations Client
-	orgClient := organizations.NewFromConfig(cfg)
-	orgDetector := scanner.NewOrgDetector(orgClient, csvLogger)
+	if len(targets) > 1 {
+		utils.PrintSubtotals("Target", names, targetTotals)
+		fmt.Printf("\nCombined totals of %d targets:\n\n", len(names))
+		utils.PrintTotals(combined)
+		utils.PrintFailedTargets(failed)
+	}
+	utils.PrintRetryStats(retries.Stats())
 
-	// Create Scanner service with factory function for ResourceScanner
-	scanService := scanner.NewScanner(
-		stsClient,
-		sessionManager,
-		regionsManager,
-		credsManager,
-		orgDetector,
-		func(cfg aws.Config) interfaces.OrganizationsClient {
-			return organizations.NewFromConfig(cfg)
-		},
-		csvLogger,
-	)
+	if len(exceeded) > 0 || len(failed) > 0 {
+		csvLogger.Close()
+		capacityLogger.Close()
+		if inventoryLogger != nil {
+			inventoryLogger.Close()
+		}
+	}
+	if len(failed) > 0 {
+		log.Fatalf("%d of %d targets failed", len(failed), len(targets))
+	}
+	if len(exceeded) > 0 {
+		log.Fatalf("%d discrepancies exceed the tolerance of %.1f%%", len(exceeded), userConfig.Tolerance)
+	}
+}
+
+// runPreflight checks role assumption and one call per counter in the caller
+// account and a sample of the accounts of every target, and prints a matrix of
+// the results with the missing IAM actions.
+func runPreflight(ctx context.Context, userConfig config.Config) {
+	preflightLogger, err := logger.InitLogger("aws-resource-discovery-preflight.log")
+	if err != nil {
+		log.Fatalf("Failed to initialize preflight logger: %v", err)
+	}
+	defer preflightLogger.Close()
+
+	retries := utils.NewRetries(userConfig.MaxAttempts)
+	failed := 0
+	failedTargets := map[string]error{}
+	targets := scanTargets(userConfig)
+	for _, target := range targets {
+		targetConfig := target.Apply(userConfig)
+		if err := targetConfig.Validate(); err != nil {
+			log.Printf("Invalid target %s: %v", target.Name, err)
+			failedTargets[target.Name] = err
+			continue
+		}
+		if target.Name != "" {
+			fmt.Printf("Checking target %s.\n", target.Name)
+		}
+		scanService, _, partition, err := newScanService(ctx, targetConfig, retries, preflightLogger)
+		if err != nil {
+			log.Printf("Failed to check target %s: %v", target.Name, err)
+			failedTargets[target.Name] = err
+			continue
+		}
+		checks := preflight.Checks(targetConfig, partition)
+		results, err := scanService.Preflight(ctx, targetConfig, checks)
+		if err != nil {
+			log.Printf("Failed to run preflight of target %s: %v", target.Name, err)
+			failedTargets[target.Name] = err
+			continue
+		}
+		preflight.PrintMatrix(results, checks)
+		for _, result := range results {
+			if !result.Passed() {
+				failed++
+			}
+		}
+	}
+
+	if len(targets) > 1 {
+		utils.PrintFailedTargets(failedTargets)
+	}
+
+	if failed > 0 || len(failedTargets) > 0 {
+		preflightLogger.Close()
+		log.Fatalf("Preflight failed in %d accounts and %d targets", failed, len(failedTargets))
+	}
+}
+
+// scanTargets returns the targets of the TARGETS file, one target per profile
+// of PROFILE, or a single target with the default credentials.
+func scanTargets(userConfig config.Config) []config.Target {
+	if userConfig.TargetsFile != "" {
+		targets, err := config.LoadTargets(userConfig.TargetsFile)
+		if err != nil {
+			log.Fatalf("Failed to load TARGETS: %v", err)
+		}
+		return targets
+	}
+	targets := []config.Target{}
+	for _, profile := range userConfig.Profiles {
+		targets = append(targets, config.Target{Name: profile, Profile: profile})
+	}
+	if len(targets) == 0 {
+		targets = append(targets, config.Target{})
+	}
+	return targets
+}
+
+// scanTarget scans with the credentials of the profile of the configuration
+// and prints its reports. Accounts in scanned are skipped, and the scanned
+// accounts are added to it. It returns the scan result and the discrepancies
+// above the tolerance, or an error when the target could not be scanned.
+func scanTarget(ctx context.Context, userConfig config.Config, scanned map[string]string, retries *utils.Retries, csvLogger, inventoryLogger, capacityLogger interfaces.Logger) (scanner.ScanResult, []interfaces.Discrepancy, error) {
+	tagFiltered := len(userConfig.IncludeTags) > 0 || len(userConfig.ExcludeTags) > 0
+	scanService, cfg, _, err := newScanService(ctx, userConfig, retries, csvLogger)
+	if err != nil {
+		return scanner.ScanResult{}, nil, err
+	}
+	scanService.InventoryLogger = inventoryLogger
+	scanService.CapacityLogger = capacityLogger
+	scanService.ScannedAccounts = scanned
 
 	startTime := time.Now()
 
 	// Determine the flow based on the parsed configuration
 	var scanResult scanner.ScanResult
-	if userConfig.AccountId != "" {
+	if userConfig.Source == config.SourceConfigAggregator {
+		fmt.Printf("AWS Config aggregator scan selected: %s\n", userConfig.AggregatorName)
+		scanResult, err = scanService.ScanConfigAggregator(ctx, userConfig)
+	} else if userConfig.AccountId != "" {
 		fmt.Println("Single account scan selected.")
 		scanResult, err = scanService.ScanSingleAccount(ctx, userConfig)
+	} else if len(userConfig.IncludeAccounts) > 0 {
+		fmt.Printf("Account list scan selected: %d accounts.\n", len(userConfig.IncludeAccounts))
+		scanResult, err = scanService.ScanAccounts(ctx, userConfig)
 	} else {
 		fmt.Println("Organization scan selected.")
 		scanResult, err = scanService.ScanOrganization(ctx, userConfig)
 	}
 
 	if err != nil {
-		log.Fatalf("Failed to perform scan: %v", err)
+		return scanner.ScanResult{}, nil, fmt.Errorf("failed to perform scan: %w", err)
 	}
 
 	endTime := time.Now()
@@ -95,6 +276,26 @@ func main() {
 		fmt.Printf("\nScan completed in %d seconds.\n", seconds)
 	}
 
+	if scanResult.Report != nil {
+		utils.PrintSkippedAccounts(scanResult.Report.SkippedAccounts)
+		if len(userConfig.RoleNames) > 1 || userConfig.RoleMapFile != "" {
+			utils.PrintAccountRoles(scanResult.Report.AccountRoles)
+		}
+		utils.PrintRegionStatuses(scanResult.Report.RegionStatuses)
+		utils.PrintPermissionSuggestions(scanResult.Report.PermissionSuggestions, scanResult.Report.DeniedActions)
+	}
+
+	if tagFiltered && scanResult.Report != nil {
+		utils.PrintFiltered(scanResult.Report.Filtered, scanResult.Report.Unfiltered)
+	}
+
+	// Compare the native and CloudControl counts
+	var exceeded []interfaces.Discrepancy
+	if userConfig.Reconcile && scanResult.Report != nil {
+		utils.PrintDiscrepancies(scanResult.Report.Discrepancies)
+		exceeded = scanResult.Report.DiscrepanciesAbove(userConfig.Tolerance)
+	}
+
 	// Perform CloudTrail check based on the scan result
 	if userConfig.Trail {
 		ctClient := aws_trail.NewFromConfig(cfg)
@@ -106,31 +307,231 @@ func main() {
 			AWSConfig: cfg,
 		}
 
+		// The counts of the target are kept when its trails cannot be checked
 		trailInfos, err := ctChecker.CheckCloudTrail(ctx, scanResult)
 		if err != nil {
-			log.Fatalf("Failed to check CloudTrail: %v", err)
+			log.Printf("Failed to check CloudTrail: %v", err)
+		} else {
+			cloudtrail.PrintTable(ctx, trailInfos)
 		}
-		cloudtrail.PrintTable(ctx, trailInfos)
 	}
+
+	return scanResult, exceeded, nil
+}
+
+// newScanService creates the scanner of the profile of the configuration, whose
+// clients all retry with the retries configuration. It returns the scanner, the
+// configuration of the caller and its partition, or an error when the
+// credentials of the profile cannot be used.
+func newScanService(ctx context.Context, userConfig config.Config, retries *utils.Retries, csvLogger interfaces.Logger) (*scanner.Scanner, aws.Config, string, error) {
+	// Load the AWS SDK configuration
+	cfg, err := utils.LoadAWSConfig(ctx, userConfig.Profile, "", retries)
+	if err != nil {
+		return nil, aws.Config{}, "", fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if err := utils.CheckCredentials(ctx, cfg, userConfig.Profile); err != nil {
+		return nil, aws.Config{}, "", fmt.Errorf("invalid AWS credentials: %w", err)
+	}
+
+	// Create STS Client
+	stsClient := sts.NewFromConfig(cfg)
+
+	// Roles are assumed in the scanned accounts from the last role of the
+	// chain, which starts from the role of AWS_ROLE_ARN when it is set
+	accountsSTSClient := stsClient
+	if len(userConfig.RoleChain) > 0 {
+		chainedCfg := managers.ChainRoles(cfg, userConfig.Chain(), func(cfg aws.Config) stscreds.AssumeRoleAPIClient {
+			return sts.NewFromConfig(cfg)
+		})
+		accountsSTSClient = sts.NewFromConfig(chainedCfg)
+	}
+	// Role ARNs use the partition of the caller, e.g. aws-us-gov in GovCloud
+	partition := utils.DetectPartition(ctx, accountsSTSClient, cfg.Region)
+	options, err := credentialsOptions(userConfig)
+	if err != nil {
+		return nil, aws.Config{}, "", err
+	}
+	options = append(options, managers.WithPartition(partition))
+	credsManager := managers.NewCredentialsManager(roleName(userConfig), accountsSTSClient, options...)
+	sessionManager := managers.NewSessionManager(stsClient, retries)
+
+	// Create EC2 Client
+	ec2Client := ec2.NewFromConfig(cfg)
+
+	// Create RegionsManager with EC2 Client
+	regionsManager := managers.NewRegionManager(ec2Client)
+
+	// Create Organizations Client
+	orgClient := organizations.NewFromConfig(cfg)
+	orgDetector := scanner.NewOrgDetector(orgClient, csvLogger)
+
+	// Create Scanner service with factory function for ResourceScanner
+	scanService := scanner.NewScanner(
+		stsClient,
+		sessionManager,
+		regionsManager,
+		credsManager,
+		orgDetector,
+		func(cfg aws.Config) interfaces.OrganizationsClient {
+			return organizations.NewFromConfig(cfg)
+		},
+		csvLogger,
+	)
+	scanService.Retries = retries
+	scanService.Partition = partition
+	scanService.IAMClientFactory = func(cfg aws.Config) interfaces.IAMClient {
+		return iam.NewFromConfig(cfg)
+	}
+	scanService.ConfigClientFactory = func(cfg aws.Config) interfaces.ConfigServiceClient {
+		return configservice.NewFromConfig(cfg)
+	}
+	scanService.ExplorerClientFactory = func(cfg aws.Config) interfaces.ResourceExplorerClient {
+		// Searches must be sent to the region that hosts the view.
+		return resourceexplorer2.NewFromConfig(cfg, func(o *resourceexplorer2.Options) {
+			if viewArn, err := arn.Parse(userConfig.ExplorerViewArn); err == nil {
+				o.Region = viewArn.Region
+			}
+		})
+	}
+	return scanService, cfg, partition, nil
+}
+
+// roleName returns the first role name to assume in every account.
+func roleName(userConfig config.Config) string {
+	if len(userConfig.RoleNames) == 0 {
+		return ""
+	}
+	return userConfig.RoleNames[0]
+}
+
+// credentialsOptions returns the options of the credentials manager set by the flags.
+func credentialsOptions(userConfig config.Config) ([]managers.CredentialsOption, error) {
+	options := []managers.CredentialsOption{
+		managers.WithExternalId(userConfig.ExternalId),
+		managers.WithDuration(userConfig.RoleDuration),
+		managers.WithSourceIdentity(userConfig.SourceIdentity),
+	}
+	if len(userConfig.RoleNames) > 1 {
+		options = append(options, managers.WithFallbackRoleNames(userConfig.RoleNames[1:]...))
+	}
+	if userConfig.RoleMapFile != "" {
+		roleMap, err := utils.LoadRoleMap(userConfig.RoleMapFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ROLE_MAP: %w", err)
+		}
+		options = append(options, managers.WithRoleMap(roleMap))
+	}
+	return options, nil
 }
 
 func parseFlags() config.Config {
 	var config config.Config
+	var roleNames string
+	var roleDuration string
+	var roleChain string
+	var includeAccounts string
 	var excludeAccounts string
+	var includeNames string
+	var excludeNames string
+	var includeOUs string
+	var excludeOUs string
+	var lookback string
+	var includeTags string
+	var excludeTags string
+	var profiles string
+	var regions string
+	var excludedRegions string
 
+	flag.StringVar(&profiles, "PROFILE", "", "Named AWS profile to scan with, or a comma-separated list of profiles scanned in turn into one report")
+	flag.IntVar(&config.MaxAttempts, "MAX_ATTEMPTS", utils.DefaultMaxAttempts, "Number of attempts of every AWS request, with adaptive backoff on throttling errors")
+	flag.IntVar(&config.PreflightSample, "PREFLIGHT_SAMPLE", 5, "Number of accounts checked by the preflight command besides the caller account, or 0 for every account")
+	flag.StringVar(&config.TargetsFile, "TARGETS", "", "Path of a JSON file listing the organizations to scan, each with its profile or role ARN, role names, account and organizational unit filters and regions")
 	flag.StringVar(&config.RoleArn, "AWS_ROLE_ARN", "", "AWS Role ARN to assume")
 	flag.StringVar(&config.AccountId, "AWS_ACCOUNT_ID", "", "AWS Account ID")
 	flag.StringVar(&config.Region, "AWS_REGION", "", "AWS Region")
-	flag.StringVar(&config.RoleName, "AWS_ROLE_NAME", "", "AWS Role Name")
+	flag.StringVar(&regions, "REGIONS", "", "Comma-separated list of regions to scan; defaults to every region enabled in each account")
+	flag.StringVar(&excludedRegions, "EXCLUDE_REGIONS", "", "Comma-separated list of regions not to scan")
+	flag.StringVar(&roleNames, "AWS_ROLE_NAME", "", "AWS Role Name, or a comma-separated list of role names tried in order in every account")
+	flag.StringVar(&config.RoleMapFile, "ROLE_MAP", "", "Path of a JSON file mapping account IDs to the role name, or list of role names, to assume in them")
+	flag.StringVar(&config.ExternalId, "EXTERNAL_ID", "", "External ID passed when assuming the role in every account")
+	flag.StringVar(&roleDuration, "ROLE_DURATION", "", "Duration of the role sessions in every account, e.g. 2h; defaults to the role setting")
+	flag.StringVar(&roleChain, "ROLE_CHAIN", "", "Comma-separated list of role ARNs, each optionally followed by |external-id, assumed in turn before the role in every account")
+	flag.StringVar(&config.SourceIdentity, "SOURCE_IDENTITY", "", "Source identity set on the role sessions in every account")
 	flag.BoolVar(&config.Trail, "AWS_TRAIL", false, "Set to true to print CloudTrail information")
-	flag.StringVar(&excludeAccounts, "EXCLUDE", "", "Comma-separated list of AWS account numbers to exclude")
+	flag.StringVar(&includeAccounts, "INCLUDE", "", "Comma-separated list of AWS account numbers to scan, or @ followed by the path of a file listing them")
+	flag.StringVar(&excludeAccounts, "EXCLUDE", "", "Comma-separated list of AWS account numbers to exclude, or @ followed by the path of a file listing them")
+	flag.StringVar(&includeNames, "INCLUDE_NAMES", "", "Comma-separated list of glob or /regex/ patterns; only accounts whose name or email matches one are scanned")
+	flag.StringVar(&excludeNames, "EXCLUDE_NAMES", "", "Comma-separated list of glob or /regex/ patterns; accounts whose name or email matches one are not scanned")
+	flag.StringVar(&includeOUs, "INCLUDE_OUS", "", "Comma-separated list of organizational unit IDs or paths, e.g. Root/Workloads; only their accounts are scanned")
+	flag.StringVar(&excludeOUs, "EXCLUDE_OUS", "", "Comma-separated list of organizational unit IDs or paths whose accounts are not scanned")
+	flag.BoolVar(&config.SimulatePolicies, "SIMULATE_POLICIES", false, "Set to true to simulate the policies of the role in every account with iam:SimulatePrincipalPolicy and report the denied actions with the policy type that denied them")
+	flag.BoolVar(&config.ForceAssumeRole, "FORCE_ASSUME_ROLE", false, "Set to true to assume the scanning role in the account of the caller credentials too, instead of scanning it with those credentials")
+	flag.StringVar(&config.Source, "SOURCE", "", "Source of resource counts: config-aggregator or resource-explorer; defaults to the per-account counters")
+	flag.StringVar(&config.AggregatorName, "AGGREGATOR_NAME", "", "Name of the AWS Config aggregator used with SOURCE=config-aggregator")
+	flag.StringVar(&config.ExplorerViewArn, "RESOURCE_EXPLORER_VIEW", "", "ARN of the Resource Explorer view used with SOURCE=resource-explorer; defaults to the default view of the region")
+	flag.StringVar(&config.CountStrategy, "STRATEGY", "native", "Counting strategy: native list APIs with CloudControl as the fallback, or cloudcontrol for every supported type")
+	flag.BoolVar(&config.Reconcile, "RECONCILE", false, "Set to true to compare the native counts with CloudControl and report discrepancies")
+	flag.Float64Var(&config.Tolerance, "TOLERANCE", 0, "Percentage of mismatched resources per account, region and type tolerated in RECONCILE mode before exiting with an error")
+	flag.BoolVar(&config.Inventory, "INVENTORY", false, "Set to true to write every counted resource to aws-resource-discovery-inventory.csv")
+	flag.StringVar(&includeTags, "INCLUDE_TAGS", "", "Comma-separated list of key=value or key tags; only resources with one of them are counted")
+	flag.StringVar(&excludeTags, "EXCLUDE_TAGS", "", "Comma-separated list of key=value or key tags; resources with any of them are not counted")
+	flag.StringVar(&config.GroupByTag, "GROUP_BY_TAG", "", "Tag key whose values split the totals, e.g. cost-center")
+	flag.StringVar(&lookback, "LOOKBACK", "", "Window of CloudWatch metrics used to report peak counts, e.g. 30d")
 
 	// Parse flags
 	flag.Parse()
 
-	// Split the EXCLUDE_ACCOUNT flag value into a slice of strings
-	if excludeAccounts != "" {
-		config.ExcludeAccounts = strings.Split(excludeAccounts, ",")
+	var err error
+	if profiles != "" {
+		config.Profiles = strings.Split(profiles, ",")
+	}
+	if regions != "" {
+		config.Regions = strings.Split(regions, ",")
+	}
+	if excludedRegions != "" {
+		config.ExcludeRegions = strings.Split(excludedRegions, ",")
+	}
+	if roleNames != "" {
+		config.RoleNames = strings.Split(roleNames, ",")
+	}
+	if roleDuration != "" {
+		if config.RoleDuration, err = time.ParseDuration(roleDuration); err != nil {
+			log.Fatalf("Failed to parse ROLE_DURATION: %v", err)
+		}
+	}
+	if config.RoleChain, err = utils.ParseRoleChain(roleChain); err != nil {
+		log.Fatalf("Failed to parse ROLE_CHAIN: %v", err)
+	}
+	if config.IncludeAccounts, err = utils.ParseAccountList(includeAccounts); err != nil {
+		log.Fatalf("Failed to parse INCLUDE: %v", err)
+	}
+	if config.ExcludeAccounts, err = utils.ParseAccountList(excludeAccounts); err != nil {
+		log.Fatalf("Failed to parse EXCLUDE: %v", err)
+	}
+	if config.IncludeNames, err = utils.ParseAccountPatterns(includeNames); err != nil {
+		log.Fatalf("Failed to parse INCLUDE_NAMES: %v", err)
+	}
+	if config.ExcludeNames, err = utils.ParseAccountPatterns(excludeNames); err != nil {
+		log.Fatalf("Failed to parse EXCLUDE_NAMES: %v", err)
+	}
+	if includeOUs != "" {
+		config.IncludeOUs = strings.Split(includeOUs, ",")
+	}
+	if excludeOUs != "" {
+		config.ExcludeOUs = strings.Split(excludeOUs, ",")
+	}
+
+	lookbackDuration, err := utils.ParseLookback(lookback)
+	if err != nil {
+		log.Fatalf("Failed to parse LOOKBACK: %v", err)
+	}
+	config.Lookback = lookbackDuration
+
+	if config.IncludeTags, err = utils.ParseTagFilters(includeTags); err != nil {
+		log.Fatalf("Failed to parse INCLUDE_TAGS: %v", err)
+	}
+	if config.ExcludeTags, err = utils.ParseTagFilters(excludeTags); err != nil {
+		log.Fatalf("Failed to parse EXCLUDE_TAGS: %v", err)
 	}
 
 	return config
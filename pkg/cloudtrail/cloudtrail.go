@@ -3,10 +3,12 @@ package cloudtrail
 import (
 	"aws-resource-discovery/pkg/interfaces"
 	"aws-resource-discovery/pkg/scanner"
+	"aws-resource-discovery/pkg/utils"
 	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -62,7 +64,7 @@ func (c *CloudTrailChecker) createTrailInfo(ctx context.Context, accountId strin
 	}
 
 	if trail.S3BucketName != nil {
-		trailInfo.S3BucketArn = fmt.Sprintf("arn:aws:s3:::%s", aws.ToString(trail.S3BucketName))
+		trailInfo.S3BucketArn = fmt.Sprintf("arn:%s:s3:::%s", c.partition(trailInfo.TrailArn), aws.ToString(trail.S3BucketName))
 		bucketRegion, err := c.getBucketRegion(ctx, aws.ToString(trail.S3BucketName))
 		if err != nil {
 			return trailInfo, fmt.Errorf("failed to get bucket region for bucket %s: %w", aws.ToString(trail.S3BucketName), err)
@@ -111,13 +113,23 @@ func (c *CloudTrailChecker) getBucketRegion(ctx context.Context, bucketName stri
 		return "", fmt.Errorf("failed to get bucket location for bucket %s: %w", bucketName, err)
 	}
 
+	// Buckets in the global region of a partition have no location constraint
 	if locationOutput.LocationConstraint == "" {
-		return "us-east-1", nil
+		return utils.GlobalRegion(c.partition("")), nil
 	}
 
 	return string(locationOutput.LocationConstraint), nil
 }
 
+// partition returns the partition of a trail ARN, or of the configured region
+// when the ARN cannot be parsed.
+func (c *CloudTrailChecker) partition(trailArn string) string {
+	if parsed, err := arn.Parse(trailArn); err == nil {
+		return parsed.Partition
+	}
+	return utils.PartitionForRegion(c.AWSConfig.Region)
+}
+
 func PrintTable(ctx context.Context, trailInfos []TrailInfo) {
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
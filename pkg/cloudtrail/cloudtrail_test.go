@@ -105,3 +105,17 @@ func TestMockS3Client_GetBucketNotificationConfiguration(t *testing.T) {
 	assert.Nil(t, output)
 	mockClient.AssertExpectations(t)
 }
+
+func TestCloudTrailChecker_getBucketRegionInGovCloud(t *testing.T) {
+	mockS3 := new(mocks.MockS3Client)
+	checker := &CloudTrailChecker{S3Client: mockS3, AWSConfig: aws.Config{Region: "us-gov-east-1"}}
+	mockS3.On("GetBucketLocation", context.TODO(), &s3.GetBucketLocationInput{Bucket: aws.String("trail-bucket")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+
+	region, err := checker.getBucketRegion(context.TODO(), "trail-bucket")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "us-gov-west-1", region)
+	assert.Equal(t, "aws-cn", checker.partition("arn:aws-cn:cloudtrail:cn-north-1:123456789012:trail/MyTrail"))
+	assert.Equal(t, "aws-us-gov", checker.partition(""))
+}
@@ -1,11 +1,172 @@
 package config
 
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// SourceConfigAggregator selects an AWS Config aggregator as the source of resource counts.
+const SourceConfigAggregator = "config-aggregator"
+
+// SourceResourceExplorer selects a Resource Explorer aggregator index as the source
+// of resource counts, with the normal counters as the fallback.
+const SourceResourceExplorer = "resource-explorer"
+
+// StrategyNative counts resources with the list APIs of their services, using
+// CloudControl only for resource types without a native implementation.
+const StrategyNative = "native"
+
+// StrategyCloudControl counts every CloudControl-backed resource type with CloudControl.
+const StrategyCloudControl = "cloudcontrol"
+
+// TagFilter matches resources carrying a tag. An empty Value matches any value of the key.
+type TagFilter struct {
+	Key   string
+	Value string
+}
+
+// String returns the filter in the key=value form it was given in.
+func (f TagFilter) String() string {
+	if f.Value == "" {
+		return f.Key
+	}
+	return f.Key + "=" + f.Value
+}
+
+// Matches reports whether the tags contain the filtered tag.
+func (f TagFilter) Matches(tags map[string]string) bool {
+	value, ok := tags[f.Key]
+	return ok && (f.Value == "" || value == f.Value)
+}
+
+// AccountPattern matches accounts by name or email, with either a regular
+// expression given as /expression/ or a case-insensitive glob such as "prod-*".
+type AccountPattern struct {
+	Pattern string
+	Regexp  *regexp.Regexp
+}
+
+// String returns the pattern as it was given.
+func (p AccountPattern) String() string {
+	return p.Pattern
+}
+
+// Matches reports whether any of the values, such as an account name and email, matches the pattern.
+func (p AccountPattern) Matches(values ...string) bool {
+	for _, value := range values {
+		if p.Regexp.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChainedRole is a role assumed on the way to the accounts that are scanned.
+type ChainedRole struct {
+	RoleArn    string
+	ExternalId string
+}
+
 // Config defines the configuration for the scanner.
 type Config struct {
 	RoleArn         string
 	AccountId       string
 	Region          string
-	RoleName        string
+	RoleNames       []string
+	RoleMapFile     string
+	ExternalId      string
+	RoleDuration    time.Duration
+	SourceIdentity  string
+	RoleChain       []ChainedRole
 	Trail           bool
 	ExcludeAccounts []string
+	Lookback        time.Duration
+	Source          string
+	AggregatorName  string
+	ExplorerViewArn string
+	CountStrategy   string
+	Reconcile       bool
+	Tolerance       float64
+	Inventory       bool
+	IncludeTags     []TagFilter
+	ExcludeTags     []TagFilter
+	GroupByTag      string
+	IncludeOUs      []string
+	ExcludeOUs      []string
+	IncludeAccounts []string
+	IncludeNames    []AccountPattern
+	ExcludeNames    []AccountPattern
+	ForceAssumeRole bool
+	// Profiles lists the named profiles scanned in turn into one report.
+	Profiles []string
+	// Profile is the named profile of the scan in progress, or empty for the
+	// default credentials.
+	Profile string
+	// Regions limits the scan to the listed regions when set.
+	Regions []string
+	// ExcludeRegions lists the regions left out of the scan.
+	ExcludeRegions []string
+	// TargetsFile is the path of the JSON file listing the targets to scan.
+	TargetsFile string
+	// Target is the name of the target of the scan in progress.
+	Target string
+	// MaxAttempts is the number of attempts of every AWS request.
+	MaxAttempts int
+	// PreflightSample is the number of accounts checked by the preflight
+	// command besides the caller account, or 0 for every account.
+	PreflightSample int
+	// SimulatePolicies simulates the policies of the scanning role of every
+	// account for the actions the counters need.
+	SimulatePolicies bool
+}
+
+// Validate rejects combinations of options that cannot be used together.
+func (c Config) Validate() error {
+	if c.Source == SourceConfigAggregator && c.AggregatorName == "" {
+		return errors.New("AGGREGATOR_NAME is required when SOURCE is " + SourceConfigAggregator)
+	}
+	if c.CountStrategy != StrategyNative && c.CountStrategy != StrategyCloudControl {
+		return errors.New("STRATEGY must be " + StrategyNative + " or " + StrategyCloudControl)
+	}
+	tagFiltered := len(c.IncludeTags) > 0 || len(c.ExcludeTags) > 0
+	if (tagFiltered || c.GroupByTag != "") && c.Source != "" {
+		return errors.New("INCLUDE_TAGS, EXCLUDE_TAGS and GROUP_BY_TAG need the per-account counters and cannot be used with SOURCE=" + c.Source)
+	}
+	if c.SimulatePolicies && c.Source == SourceConfigAggregator {
+		return errors.New("SIMULATE_POLICIES simulates the role assumed in every account and cannot be used with SOURCE=" + SourceConfigAggregator)
+	}
+	if c.Reconcile && c.CountStrategy == StrategyCloudControl {
+		return errors.New("RECONCILE compares the native counts with CloudControl and cannot be used with STRATEGY=" + StrategyCloudControl)
+	}
+
+	if c.Region != "" && (len(c.Regions) > 0 || len(c.ExcludeRegions) > 0) {
+		return errors.New("AWS_REGION scans a single region and cannot be used with REGIONS or EXCLUDE_REGIONS")
+	}
+	if c.AccountId != "" && len(c.Profiles) > 1 {
+		return errors.New("AWS_ACCOUNT_ID scans a single account and cannot be used with more than one PROFILE")
+	}
+	if c.TargetsFile != "" && (c.AccountId != "" || len(c.Profiles) > 0) {
+		return errors.New("TARGETS sets the profile and accounts of every target and cannot be used with AWS_ACCOUNT_ID or PROFILE")
+	}
+
+	orgSelection := len(c.ExcludeAccounts) > 0 || len(c.IncludeOUs) > 0 || len(c.ExcludeOUs) > 0 ||
+		len(c.IncludeNames) > 0 || len(c.ExcludeNames) > 0
+	if c.AccountId != "" && (orgSelection || len(c.IncludeAccounts) > 0) {
+		return errors.New("AWS_ACCOUNT_ID scans a single account and cannot be used with INCLUDE, EXCLUDE, INCLUDE_OUS, EXCLUDE_OUS, INCLUDE_NAMES or EXCLUDE_NAMES")
+	}
+	if len(c.IncludeAccounts) > 0 && orgSelection {
+		return errors.New("INCLUDE scans exactly the listed accounts and cannot be used with EXCLUDE, INCLUDE_OUS, EXCLUDE_OUS, INCLUDE_NAMES or EXCLUDE_NAMES")
+	}
+	return nil
+}
+
+// Chain returns the roles assumed on the way to the role of every account: the
+// role of AWS_ROLE_ARN, when set, followed by the roles of ROLE_CHAIN, so that
+// the chain starts from the session of AWS_ROLE_ARN.
+func (c Config) Chain() []ChainedRole {
+	if c.RoleArn == "" {
+		return c.RoleChain
+	}
+	return append([]ChainedRole{{RoleArn: c.RoleArn}}, c.RoleChain...)
 }
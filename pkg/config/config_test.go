@@ -0,0 +1,45 @@
+package config
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{CountStrategy: StrategyNative}.Validate())
+	assert.NoError(t, Config{CountStrategy: StrategyNative, IncludeAccounts: []string{"123456789012"}}.Validate())
+	assert.NoError(t, Config{CountStrategy: StrategyNative, AccountId: "123456789012", Profiles: []string{"prod"}}.Validate())
+	assert.NoError(t, Config{CountStrategy: StrategyNative, ExcludeAccounts: []string{"123456789012"}, IncludeOUs: []string{"Root/Workloads"}}.Validate())
+	assert.NoError(t, Config{CountStrategy: StrategyNative, Regions: []string{"us-east-1", "eu-west-1"}, ExcludeRegions: []string{"eu-west-1"}}.Validate())
+
+	assert.ErrorContains(t, Config{CountStrategy: "fast"}.Validate(), "STRATEGY")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, Source: SourceConfigAggregator}.Validate(), "AGGREGATOR_NAME")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, Source: SourceResourceExplorer, GroupByTag: "env"}.Validate(), "GROUP_BY_TAG")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyCloudControl, Reconcile: true}.Validate(), "RECONCILE")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, Source: SourceConfigAggregator, AggregatorName: "org", SimulatePolicies: true}.Validate(), "SIMULATE_POLICIES")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, AccountId: "123456789012", ExcludeAccounts: []string{"210987654321"}}.Validate(), "AWS_ACCOUNT_ID")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, AccountId: "123456789012", IncludeAccounts: []string{"210987654321"}}.Validate(), "AWS_ACCOUNT_ID")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, AccountId: "123456789012", Profiles: []string{"prod", "acquisitions"}}.Validate(), "PROFILE")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, Region: "us-east-1", ExcludeRegions: []string{"eu-west-1"}}.Validate(), "AWS_REGION")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, TargetsFile: "targets.json", Profiles: []string{"prod"}}.Validate(), "TARGETS")
+	assert.ErrorContains(t, Config{CountStrategy: StrategyNative, IncludeAccounts: []string{"123456789012"}, ExcludeOUs: []string{"Root/Sandbox"}}.Validate(), "INCLUDE scans exactly")
+}
+
+func TestConfig_Chain(t *testing.T) {
+	chain := []ChainedRole{{RoleArn: "arn:aws:iam::111111111111:role/hub", ExternalId: "hub-id"}}
+	assert.Equal(t, chain, Config{RoleChain: chain}.Chain())
+	assert.Equal(t, []ChainedRole{
+		{RoleArn: "arn:aws:iam::222222222222:role/entry"},
+		{RoleArn: "arn:aws:iam::111111111111:role/hub", ExternalId: "hub-id"},
+	}, Config{RoleArn: "arn:aws:iam::222222222222:role/entry", RoleChain: chain}.Chain())
+}
+
+func TestAccountPattern_Matches(t *testing.T) {
+	pattern := AccountPattern{Pattern: "/^prod/", Regexp: regexp.MustCompile("^prod")}
+
+	assert.Equal(t, "/^prod/", pattern.String())
+	assert.True(t, pattern.Matches("staging", "prod@example.com"))
+	assert.False(t, pattern.Matches("staging", "ops@example.com"))
+}
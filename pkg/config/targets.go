@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// accountIdPattern matches the account IDs listed in a targets file.
+var accountIdPattern = regexp.MustCompile(`^\d+$`)
+
+// Target is an organization, or a set of accounts, scanned with its own
+// credentials and account selection as part of a multi-organization scan.
+type Target struct {
+	// Name labels the totals of the target. It defaults to the profile.
+	Name       string   `json:"name"`
+	Profile    string   `json:"profile"`
+	RoleArn    string   `json:"role_arn"`
+	RoleNames  []string `json:"role_names"`
+	Include    []string `json:"include"`
+	Exclude    []string `json:"exclude"`
+	IncludeOUs []string `json:"include_ous"`
+	ExcludeOUs []string `json:"exclude_ous"`
+	Regions    []string `json:"regions"`
+}
+
+// LoadTargets reads a JSON file listing the targets to scan, such as
+// [{"name": "prod", "profile": "prod-sso", "exclude_ous": ["Root/Sandbox"]},
+// {"name": "govcloud", "profile": "gov", "regions": ["us-gov-west-1"]}].
+func LoadTargets(path string) ([]Target, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var targets []Target
+	if err := json.Unmarshal(content, &targets); err != nil {
+		return nil, fmt.Errorf("invalid targets file %s: %w", path, err)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets in %s", path)
+	}
+
+	names := map[string]bool{}
+	for i := range targets {
+		target := &targets[i]
+		if target.Name == "" {
+			target.Name = target.Profile
+		}
+		if target.Name == "" {
+			return nil, fmt.Errorf("target %d in %s needs a name or a profile", i+1, path)
+		}
+		if names[target.Name] {
+			return nil, fmt.Errorf("duplicate target %s in %s", target.Name, path)
+		}
+		names[target.Name] = true
+		for _, accountId := range append(append([]string{}, target.Include...), target.Exclude...) {
+			if !accountIdPattern.MatchString(accountId) {
+				return nil, fmt.Errorf("invalid account ID %q in target %s", accountId, target.Name)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// Apply returns the configuration of a scan of the target. The settings of the
+// target replace those of the base configuration when they are set.
+func (t Target) Apply(base Config) Config {
+	config := base
+	config.Target = t.Name
+	config.Profile = t.Profile
+	if t.RoleArn != "" {
+		config.RoleArn = t.RoleArn
+	}
+	if len(t.RoleNames) > 0 {
+		config.RoleNames = t.RoleNames
+	}
+	if len(t.Include) > 0 {
+		config.IncludeAccounts = t.Include
+	}
+	if len(t.Exclude) > 0 {
+		config.ExcludeAccounts = t.Exclude
+	}
+	if len(t.IncludeOUs) > 0 {
+		config.IncludeOUs = t.IncludeOUs
+	}
+	if len(t.ExcludeOUs) > 0 {
+		config.ExcludeOUs = t.ExcludeOUs
+	}
+	if len(t.Regions) > 0 {
+		config.Regions = t.Regions
+	}
+	return config
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTargets(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "targets.json")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadTargets(t *testing.T) {
+	path := writeTargets(t, `[
+		{"profile": "prod-sso", "exclude_ous": ["Root/Sandbox"]},
+		{"name": "acquisitions", "role_arn": "arn:aws:iam::123456789012:role/scanner", "include": ["123456789012", "210987654321"]},
+		{"name": "govcloud", "profile": "gov", "role_names": ["GovScanner"], "regions": ["us-gov-west-1"]}
+	]`)
+
+	targets, err := LoadTargets(path)
+
+	assert.NoError(t, err)
+	assert.Len(t, targets, 3)
+	assert.Equal(t, "prod-sso", targets[0].Name)
+	assert.Equal(t, []string{"Root/Sandbox"}, targets[0].ExcludeOUs)
+	assert.Equal(t, []string{"123456789012", "210987654321"}, targets[1].Include)
+	assert.Equal(t, []string{"us-gov-west-1"}, targets[2].Regions)
+}
+
+func TestLoadTargetsWithErrors(t *testing.T) {
+	_, err := LoadTargets(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	_, err = LoadTargets(writeTargets(t, `{"name": "prod"}`))
+	assert.ErrorContains(t, err, "invalid targets file")
+
+	_, err = LoadTargets(writeTargets(t, `[]`))
+	assert.ErrorContains(t, err, "no targets")
+
+	_, err = LoadTargets(writeTargets(t, `[{"role_arn": "arn:aws:iam::123456789012:role/scanner"}]`))
+	assert.ErrorContains(t, err, "needs a name")
+
+	_, err = LoadTargets(writeTargets(t, `[{"profile": "prod"}, {"name": "prod"}]`))
+	assert.ErrorContains(t, err, "duplicate target prod")
+
+	_, err = LoadTargets(writeTargets(t, `[{"profile": "prod", "exclude": ["prod-account"]}]`))
+	assert.ErrorContains(t, err, "invalid account ID")
+}
+
+func TestTarget_Apply(t *testing.T) {
+	base := Config{
+		Profile:         "default",
+		RoleNames:       []string{"red-canary-resource-discovery-role"},
+		ExcludeAccounts: []string{"111111111111"},
+		Region:          "us-east-1",
+	}
+
+	config := Target{Name: "gov", Profile: "gov", Regions: []string{"us-gov-west-1"}}.Apply(base)
+
+	assert.Equal(t, "gov", config.Target)
+	assert.Equal(t, "gov", config.Profile)
+	assert.Equal(t, []string{"red-canary-resource-discovery-role"}, config.RoleNames)
+	assert.Equal(t, []string{"111111111111"}, config.ExcludeAccounts)
+	assert.Equal(t, []string{"us-gov-west-1"}, config.Regions)
+	assert.Equal(t, "default", base.Profile)
+}
@@ -0,0 +1,193 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	cw_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// AutoScalingCounter is a counter for the in-service capacity of Auto Scaling groups.
+type AutoScalingCounter struct {
+	Client           interfaces.AutoScalingClient
+	CloudWatchClient interfaces.CloudWatchClient
+	Lookback         time.Duration
+	Result           interfaces.CounterResult
+}
+
+// groupCapacity holds the capacity settings and observed size of an Auto Scaling group.
+type groupCapacity struct {
+	name      string
+	min       int
+	max       int
+	desired   int
+	inService int
+	peak      int
+	p95       int
+	average   float64
+	instances []interfaces.ResourceRecord
+}
+
+// NewAutoScalingCounter creates a new AutoScalingCounter. Peak and p95 in-service
+// counts are only collected when lookback is greater than zero.
+func NewAutoScalingCounter(client interfaces.AutoScalingClient, cloudWatchClient interfaces.CloudWatchClient, lookback time.Duration) *AutoScalingCounter {
+	return &AutoScalingCounter{
+		Client:           client,
+		CloudWatchClient: cloudWatchClient,
+		Lookback:         lookback,
+		Result:           interfaces.CounterResult{CounterClass: "AWS::AutoScaling::AutoScalingGroup"},
+	}
+}
+
+// Call performs the counting and formats the result.
+func (c *AutoScalingCounter) Call() {
+	groups, err := c.groupCapacities()
+	c.Result = c.formatResult(groups, err)
+	if err != nil {
+		log.Printf("Error counting AWS::AutoScaling::AutoScalingGroup: %v", err)
+	}
+}
+
+// groupCapacities describes every Auto Scaling group and, when a lookback is set,
+// its peak and p95 in-service instance count.
+func (c *AutoScalingCounter) groupCapacities() ([]groupCapacity, error) {
+	groups := []groupCapacity{}
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(c.Client, &autoscaling.DescribeAutoScalingGroupsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe Auto Scaling groups: %w", err)
+		}
+		for _, group := range output.AutoScalingGroups {
+			capacity := groupCapacity{
+				name:      aws.ToString(group.AutoScalingGroupName),
+				min:       int(aws.ToInt32(group.MinSize)),
+				max:       int(aws.ToInt32(group.MaxSize)),
+				desired:   int(aws.ToInt32(group.DesiredCapacity)),
+				inService: inServiceCount(group.Instances),
+				instances: inServiceInstances(group),
+			}
+			capacity.peak = capacity.inService
+			capacity.p95 = capacity.inService
+			capacity.average = float64(capacity.inService)
+			if c.Lookback > 0 {
+				if err := c.addHistory(&capacity); err != nil {
+					log.Printf("Error reading history for AWS::AutoScaling::AutoScalingGroup: %v", err)
+				}
+			}
+			groups = append(groups, capacity)
+		}
+	}
+	return groups, nil
+}
+
+// addHistory sets the peak and p95 in-service count of a group from its GroupInServiceInstances metric.
+// Groups without metrics collection enabled, or whose metrics cannot be read, keep their current count.
+func (c *AutoScalingCounter) addHistory(capacity *groupCapacity) error {
+	query := cw_types.MetricDataQuery{
+		Id: aws.String("inservice"),
+		MetricStat: &cw_types.MetricStat{
+			Metric: &cw_types.Metric{
+				Namespace:  aws.String("AWS/AutoScaling"),
+				MetricName: aws.String("GroupInServiceInstances"),
+				Dimensions: []cw_types.Dimension{
+					{Name: aws.String("AutoScalingGroupName"), Value: aws.String(capacity.name)},
+				},
+			},
+			Period: aws.Int32(metricPeriod(c.Lookback)),
+			Stat:   aws.String("Maximum"),
+		},
+	}
+	values, err := metricValues(context.TODO(), c.CloudWatchClient, query, c.Lookback)
+	if err != nil {
+		return fmt.Errorf("failed to get in-service metrics for Auto Scaling group %s: %w", capacity.name, err)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if peak := int(maxValue(values)); peak > capacity.peak {
+		capacity.peak = peak
+	}
+	capacity.p95 = int(percentile(values, 95))
+	capacity.average = mean(values)
+	return nil
+}
+
+// inServiceCount counts the instances of a group that are in service.
+func inServiceCount(instances []types.Instance) int {
+	count := 0
+	for _, instance := range instances {
+		if instance.LifecycleState == types.LifecycleStateInService {
+			count++
+		}
+	}
+	return count
+}
+
+// inServiceInstances describes the in-service instances of a group, tagged with
+// the tags the group propagates to them.
+func inServiceInstances(group types.AutoScalingGroup) []interfaces.ResourceRecord {
+	tags := map[string]string{}
+	for _, tag := range group.Tags {
+		if aws.ToBool(tag.PropagateAtLaunch) {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+	records := []interfaces.ResourceRecord{}
+	for _, instance := range group.Instances {
+		if instance.LifecycleState == types.LifecycleStateInService {
+			records = append(records, interfaces.ResourceRecord{
+				Identifier: aws.ToString(instance.InstanceId),
+				State:      string(instance.LifecycleState),
+				Tags:       tags,
+			})
+		}
+	}
+	return records
+}
+
+// formatResult formats the group capacities and includes any error.
+func (c *AutoScalingCounter) formatResult(groups []groupCapacity, err error) interfaces.CounterResult {
+	result := interfaces.CounterResult{
+		CounterClass: "AWS::AutoScaling::AutoScalingGroup",
+		Error:        err,
+		Source:       SourceNative,
+	}
+	if err != nil {
+		result.PermissionSuggestion = c.permissionSuggestion()
+		return result
+	}
+	result.HasHistory = c.Lookback > 0
+	for _, group := range groups {
+		result.Count += group.inService
+		result.Resources = append(result.Resources, group.instances...)
+		peak, p95 := "", ""
+		if c.Lookback > 0 {
+			result.Peak += group.peak
+			result.Average += group.average
+			peak, p95 = strconv.Itoa(group.peak), strconv.Itoa(group.p95)
+		}
+		result.Details = append(result.Details, []string{group.name, strconv.Itoa(group.min), strconv.Itoa(group.max), strconv.Itoa(group.desired), strconv.Itoa(group.inService), peak, p95})
+	}
+	return result
+}
+
+// permissionSuggestion returns the permissions needed for counting Auto Scaling groups.
+func (c *AutoScalingCounter) permissionSuggestion() string {
+	if c.Lookback > 0 {
+		return permissionSuggestion("Auto Scaling groups", "AWS::AutoScaling::AutoScalingGroup", HistoryPermission)
+	}
+	return permissionSuggestion("Auto Scaling groups", "AWS::AutoScaling::AutoScalingGroup")
+}
+
+// GetResult returns the counter result.
+func (c *AutoScalingCounter) GetResult() interfaces.CounterResult {
+	return c.Result
+}
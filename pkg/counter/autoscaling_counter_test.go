@@ -0,0 +1,136 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cw_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testAutoScalingGroups() *autoscaling.DescribeAutoScalingGroupsOutput {
+	return &autoscaling.DescribeAutoScalingGroupsOutput{
+		AutoScalingGroups: []types.AutoScalingGroup{
+			{
+				AutoScalingGroupName: aws.String("web"),
+				MinSize:              aws.Int32(1),
+				MaxSize:              aws.Int32(10),
+				DesiredCapacity:      aws.Int32(2),
+				Instances: []types.Instance{
+					{LifecycleState: types.LifecycleStateInService},
+					{LifecycleState: types.LifecycleStateInService},
+					{LifecycleState: types.LifecycleStatePending},
+				},
+			},
+		},
+	}
+}
+
+func TestAutoScalingCounter_Call(t *testing.T) {
+	mockClient := new(mocks.MockAutoScalingClient)
+	counter := NewAutoScalingCounter(mockClient, nil, 0)
+
+	// Test successful call without lookback
+	mockClient.On("DescribeAutoScalingGroups", mock.Anything, mock.Anything).Return(testAutoScalingGroups(), nil).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 2, counter.Result.Count)
+	assert.Equal(t, 0, counter.Result.Peak)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, "AWS::AutoScaling::AutoScalingGroup", counter.Result.CounterClass)
+	assert.Equal(t, [][]string{{"web", "1", "10", "2", "2", "", ""}}, counter.Result.Details)
+
+	// Test call with error
+	expectedError := errors.New("test error")
+	mockClient.On("DescribeAutoScalingGroups", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 0, counter.Result.Count)
+	assert.ErrorIs(t, counter.Result.Error, expectedError)
+	assert.Contains(t, counter.Result.PermissionSuggestion, "autoscaling:DescribeAutoScalingGroups")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestAutoScalingCounter_CallWithLookback(t *testing.T) {
+	mockClient := new(mocks.MockAutoScalingClient)
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	counter := NewAutoScalingCounter(mockClient, mockCloudWatch, 30*24*time.Hour)
+
+	values := []float64{}
+	for i := 1; i <= 20; i++ {
+		values = append(values, float64(i))
+	}
+
+	mockClient.On("DescribeAutoScalingGroups", mock.Anything, mock.Anything).Return(testAutoScalingGroups(), nil).Once()
+	mockCloudWatch.On("GetMetricData", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricDataInput) bool {
+		stat := input.MetricDataQueries[0].MetricStat
+		return aws.ToString(stat.Metric.MetricName) == "GroupInServiceInstances" &&
+			aws.ToString(stat.Metric.Dimensions[0].Value) == "web"
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []cw_types.MetricDataResult{{Values: values}},
+	}, nil).Once()
+
+	counter.Call()
+
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, 2, counter.Result.Count)
+	assert.Equal(t, 20, counter.Result.Peak)
+	assert.Equal(t, [][]string{{"web", "1", "10", "2", "2", "20", "19"}}, counter.Result.Details)
+
+	mockClient.AssertExpectations(t)
+	mockCloudWatch.AssertExpectations(t)
+}
+
+func TestAutoScalingCounter_CallWithoutMetrics(t *testing.T) {
+	mockClient := new(mocks.MockAutoScalingClient)
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	counter := NewAutoScalingCounter(mockClient, mockCloudWatch, time.Hour)
+
+	mockClient.On("DescribeAutoScalingGroups", mock.Anything, mock.Anything).Return(testAutoScalingGroups(), nil).Once()
+	mockCloudWatch.On("GetMetricData", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{}, nil).Once()
+
+	counter.Call()
+
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, 2, counter.Result.Peak)
+	assert.Equal(t, [][]string{{"web", "1", "10", "2", "2", "2", "2"}}, counter.Result.Details)
+}
+
+func TestAutoScalingCounter_CallWithMetricsError(t *testing.T) {
+	mockClient := new(mocks.MockAutoScalingClient)
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	counter := NewAutoScalingCounter(mockClient, mockCloudWatch, time.Hour)
+
+	mockClient.On("DescribeAutoScalingGroups", mock.Anything, mock.Anything).Return(testAutoScalingGroups(), nil).Once()
+	mockCloudWatch.On("GetMetricData", mock.Anything, mock.Anything).Return(nil, errors.New("access denied")).Once()
+
+	counter.Call()
+
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, 2, counter.Result.Count)
+	assert.Equal(t, 2, counter.Result.Peak)
+	assert.Equal(t, [][]string{{"web", "1", "10", "2", "2", "2", "2"}}, counter.Result.Details)
+}
+
+func TestAutoScalingCounter_GetResult(t *testing.T) {
+	counter := NewAutoScalingCounter(nil, nil, 0)
+	counter.Result = interfaces.CounterResult{
+		Count:        5,
+		CounterClass: "AWS::AutoScaling::AutoScalingGroup",
+	}
+
+	result := counter.GetResult()
+	assert.Equal(t, 5, result.Count)
+	assert.Equal(t, "AWS::AutoScaling::AutoScalingGroup", result.CounterClass)
+}
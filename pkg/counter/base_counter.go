@@ -9,42 +9,91 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 )
 
+const (
+	// SourceCloudControl marks counts produced by the CloudControl API.
+	SourceCloudControl = "cloudcontrol"
+	// SourceNative marks counts produced by the API of the counted service.
+	SourceNative = "native"
+)
+
 // BaseCounter provides common functionality for all counters.
+// Counters with a NativeListFunc list their resources with the API of the
+// counted service and only use CloudControl when it is not set, or to
+// reconcile the native listing when Reconcile is set.
 type BaseCounter struct {
 	Client                   interfaces.CloudControlClient
 	Result                   interfaces.CounterResult
 	TypeName                 string
 	PermissionSuggestionFunc func() string
+	NativeListFunc           func() ([]interfaces.ResourceRecord, error)
+	Reconcile                bool
 }
 
 // Call performs the counting and formats the result.
 func (b *BaseCounter) Call() {
-	count := b.paginatedCount()
-	b.Result = b.formatResult(count, b.Result.Error)
+	if b.NativeListFunc != nil {
+		resources, err := b.NativeListFunc()
+		b.Result = b.formatResult(len(resources), err)
+		b.Result.Resources = resources
+		if b.Reconcile && err == nil {
+			b.Result.Reconciliation = b.reconcile(resources)
+		}
+	} else {
+		resources, err := b.listResources()
+		b.Result = b.formatResult(len(resources), err)
+		b.Result.Resources = resources
+	}
 	if b.Result.Error != nil {
 		log.Printf("Error counting %s: %v", b.TypeName, b.Result.Error)
 	}
 }
 
-// paginatedCount handles paginated API requests to count resources.
-func (b *BaseCounter) paginatedCount() int {
+// listResources handles paginated CloudControl requests to list the resources
+// of the counter type.
+func (b *BaseCounter) listResources() ([]interfaces.ResourceRecord, error) {
 	input := &cloudcontrol.ListResourcesInput{
 		TypeName: aws.String(b.TypeName),
 	}
-	count := 0
+	resources := []interfaces.ResourceRecord{}
 	for {
 		result, err := b.Client.ListResources(context.TODO(), input)
 		if err != nil {
-			b.Result.Error = err
-			return 0
+			return nil, err
+		}
+		for _, description := range result.ResourceDescriptions {
+			resources = append(resources, interfaces.ResourceRecord{Identifier: aws.ToString(description.Identifier)})
 		}
-		count += len(result.ResourceDescriptions)
 		if result.NextToken == nil {
 			break
 		}
 		input.NextToken = result.NextToken
 	}
-	return count
+	return resources, nil
+}
+
+// reconcile compares the natively listed resources with the CloudControl listing.
+func (b *BaseCounter) reconcile(native []interfaces.ResourceRecord) *interfaces.Reconciliation {
+	cloudControl, err := b.listResources()
+	if err != nil {
+		log.Printf("Error reconciling %s with CloudControl: %v", b.TypeName, err)
+		return &interfaces.Reconciliation{Error: err}
+	}
+	return &interfaces.Reconciliation{
+		CloudControlCount: len(cloudControl),
+		OnlyNative:        missingFrom(native, cloudControl),
+		OnlyCloudControl:  missingFrom(cloudControl, native),
+	}
+}
+
+// enableReconciliation makes a counter with a native listing reconcile it with
+// CloudControl, and reports whether the counter supports reconciliation.
+func (b *BaseCounter) enableReconciliation(client interfaces.CloudControlClient) bool {
+	if b.NativeListFunc == nil {
+		return false
+	}
+	b.Client = client
+	b.Reconcile = true
+	return true
 }
 
 // formatResult formats the count result and includes any error.
@@ -53,6 +102,10 @@ func (b *BaseCounter) formatResult(count int, err error) interfaces.CounterResul
 		Count:        count,
 		CounterClass: b.TypeName,
 		Error:        err,
+		Source:       SourceCloudControl,
+	}
+	if b.NativeListFunc != nil {
+		result.Source = SourceNative
 	}
 	if err != nil && b.PermissionSuggestionFunc != nil {
 		result.PermissionSuggestion = b.PermissionSuggestionFunc()
@@ -64,3 +117,31 @@ func (b *BaseCounter) formatResult(count int, err error) interfaces.CounterResul
 func (b *BaseCounter) GetResult() interfaces.CounterResult {
 	return b.Result
 }
+
+// WithReconciliation makes the counters with a native listing reconcile it with
+// the CloudControl listing of the same type.
+func WithReconciliation(counters []interfaces.Counter, client interfaces.CloudControlClient) []interfaces.Counter {
+	for _, cnt := range counters {
+		if reconcilable, ok := cnt.(interface {
+			enableReconciliation(interfaces.CloudControlClient) bool
+		}); ok {
+			reconcilable.enableReconciliation(client)
+		}
+	}
+	return counters
+}
+
+// missingFrom returns the identifiers of resources that are not in others.
+func missingFrom(resources, others []interfaces.ResourceRecord) []string {
+	known := make(map[string]bool, len(others))
+	for _, other := range others {
+		known[other.Identifier] = true
+	}
+	missing := []string{}
+	for _, resource := range resources {
+		if !known[resource.Identifier] {
+			missing = append(missing, resource.Identifier)
+		}
+	}
+	return missing
+}
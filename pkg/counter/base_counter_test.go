@@ -6,6 +6,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol/types"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,7 @@ func TestBaseCounter_Call(t *testing.T) {
 	assert.Equal(t, 5, counter.Result.Count)
 	assert.Nil(t, counter.Result.Error)
 	assert.Equal(t, "TestResource", counter.Result.CounterClass)
+	assert.Len(t, counter.Result.Resources, 5)
 
 	// Test call with error
 	expectedError := errors.New("test error")
@@ -46,7 +48,7 @@ func TestBaseCounter_Call(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestBaseCounter_paginatedCount(t *testing.T) {
+func TestBaseCounter_listResources(t *testing.T) {
 	mockClient := new(mocks.MockCloudControlClient)
 	counter := &BaseCounter{
 		Client:   mockClient,
@@ -58,8 +60,9 @@ func TestBaseCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 5),
 	}, nil).Once()
 
-	count := counter.paginatedCount()
-	assert.Equal(t, 5, count)
+	resources, err := counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
 
 	// Test multiple pages
 	mockClient.On("ListResources", mock.Anything, mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
@@ -70,8 +73,9 @@ func TestBaseCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 3),
 	}, nil).Once()
 
-	count = counter.paginatedCount()
-	assert.Equal(t, 8, count)
+	resources, err = counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 8)
 
 	mockClient.AssertExpectations(t)
 }
@@ -112,3 +116,77 @@ func TestBaseCounter_GetResult(t *testing.T) {
 	assert.Equal(t, 5, result.Count)
 	assert.Equal(t, "TestResource", result.CounterClass)
 }
+
+func TestBaseCounter_CallNative(t *testing.T) {
+	mockClient := new(mocks.MockCloudControlClient)
+	counter := &BaseCounter{
+		Client:   mockClient,
+		TypeName: "TestResource",
+		PermissionSuggestionFunc: func() string {
+			return "Test permission suggestion"
+		},
+		NativeListFunc: func() ([]interfaces.ResourceRecord, error) {
+			return []interfaces.ResourceRecord{{Identifier: "a"}, {Identifier: "b"}}, nil
+		},
+	}
+
+	// CloudControl is not called when a native listing is available
+	counter.Call()
+
+	assert.Equal(t, 2, counter.Result.Count)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, SourceNative, counter.Result.Source)
+	assert.Equal(t, []interfaces.ResourceRecord{{Identifier: "a"}, {Identifier: "b"}}, counter.Result.Resources)
+	assert.Nil(t, counter.Result.Reconciliation)
+
+	expectedError := errors.New("test error")
+	counter.NativeListFunc = func() ([]interfaces.ResourceRecord, error) {
+		return nil, expectedError
+	}
+
+	counter.Call()
+
+	assert.Equal(t, 0, counter.Result.Count)
+	assert.Equal(t, expectedError, counter.Result.Error)
+	assert.Equal(t, "Test permission suggestion", counter.Result.PermissionSuggestion)
+
+	mockClient.AssertNotCalled(t, "ListResources", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBaseCounter_CallReconcile(t *testing.T) {
+	mockClient := new(mocks.MockCloudControlClient)
+	counter := &BaseCounter{
+		TypeName: "TestResource",
+		NativeListFunc: func() ([]interfaces.ResourceRecord, error) {
+			return []interfaces.ResourceRecord{{Identifier: "a"}, {Identifier: "b"}, {Identifier: "c"}}, nil
+		},
+	}
+	counters := WithReconciliation([]interfaces.Counter{counter, NewBucketCounter(nil)}, mockClient)
+	assert.True(t, counter.Reconcile)
+	assert.False(t, counters[1].(*BucketCounter).Reconcile)
+
+	mockClient.On("ListResources", mock.Anything, mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
+		ResourceDescriptions: []types.ResourceDescription{{Identifier: aws.String("b")}, {Identifier: aws.String("c")}, {Identifier: aws.String("d")}},
+	}, nil).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 3, counter.Result.Count)
+	assert.Equal(t, &interfaces.Reconciliation{
+		CloudControlCount: 3,
+		OnlyNative:        []string{"a"},
+		OnlyCloudControl:  []string{"d"},
+	}, counter.Result.Reconciliation)
+
+	// A CloudControl failure does not fail the native count
+	expectedError := errors.New("test error")
+	mockClient.On("ListResources", mock.Anything, mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{}, expectedError).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 3, counter.Result.Count)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, expectedError, counter.Result.Reconciliation.Error)
+
+	mockClient.AssertExpectations(t)
+}
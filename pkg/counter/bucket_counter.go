@@ -15,7 +15,7 @@ func NewBucketCounter(client interfaces.CloudControlClient) *BucketCounter {
 			Result:   interfaces.CounterResult{CounterClass: "AWS::S3::Bucket"},
 			TypeName: "AWS::S3::Bucket",
 			PermissionSuggestionFunc: func() string {
-				return "\nTo scan S3 buckets, the provided credentials must have the following permissions:\n- s3:ListAllMyBuckets\n- s3:GetBucketLocation\n"
+				return permissionSuggestion("S3 buckets", "AWS::S3::Bucket")
 			},
 		},
 	}
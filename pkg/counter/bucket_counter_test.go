@@ -41,7 +41,7 @@ func TestBucketCounter_Call(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestBucketCounter_paginatedCount(t *testing.T) {
+func TestBucketCounter_listResources(t *testing.T) {
 	mockClient := new(mocks.MockCloudControlClient)
 	counter := NewBucketCounter(mockClient)
 
@@ -50,8 +50,9 @@ func TestBucketCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 5),
 	}, nil).Once()
 
-	count := counter.paginatedCount()
-	assert.Equal(t, 5, count)
+	resources, err := counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
 
 	// Test multiple pages
 	mockClient.On("ListResources", mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
@@ -62,8 +63,9 @@ func TestBucketCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 3),
 	}, nil).Once()
 
-	count = counter.paginatedCount()
-	assert.Equal(t, 8, count)
+	resources, err = counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 8)
 
 	mockClient.AssertExpectations(t)
 }
@@ -0,0 +1,123 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// metricPeriod returns the finest period CloudWatch still retains for the whole lookback window.
+func metricPeriod(lookback time.Duration) int32 {
+	if lookback <= 63*24*time.Hour {
+		return 300
+	}
+	return 3600
+}
+
+// metricValues returns every datapoint of a metric query over the lookback window.
+func metricValues(ctx context.Context, client interfaces.CloudWatchClient, query types.MetricDataQuery, lookback time.Duration) ([]float64, error) {
+	end := time.Now()
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(end.Add(-lookback)),
+		EndTime:           aws.Time(end),
+		MetricDataQueries: []types.MetricDataQuery{query},
+	}
+
+	values := []float64{}
+	for {
+		output, err := client.GetMetricData(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range output.MetricDataResults {
+			values = append(values, result.Values...)
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return values, nil
+}
+
+// maxMetricDataQueries is the most queries one GetMetricData call accepts.
+const maxMetricDataQueries = 500
+
+// metricTotals returns, for every timestamp of the lookback window, the sum of
+// the datapoints of every query result at that timestamp, each first mapped
+// through value with the id of its query.
+func metricTotals(ctx context.Context, client interfaces.CloudWatchClient, queries []types.MetricDataQuery, lookback time.Duration, value func(id string, datapoint float64) float64) ([]float64, error) {
+	end := time.Now()
+	totals := map[time.Time]float64{}
+	for start := 0; start < len(queries); start += maxMetricDataQueries {
+		input := &cloudwatch.GetMetricDataInput{
+			StartTime:         aws.Time(end.Add(-lookback)),
+			EndTime:           aws.Time(end),
+			MetricDataQueries: queries[start:min(start+maxMetricDataQueries, len(queries))],
+		}
+		for {
+			output, err := client.GetMetricData(ctx, input)
+			if err != nil {
+				return nil, err
+			}
+			for _, result := range output.MetricDataResults {
+				for i, datapoint := range result.Values {
+					if i < len(result.Timestamps) {
+						totals[result.Timestamps[i]] += value(aws.ToString(result.Id), datapoint)
+					}
+				}
+			}
+			if output.NextToken == nil {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+	}
+
+	values := make([]float64, 0, len(totals))
+	for _, total := range totals {
+		values = append(values, total)
+	}
+	return values, nil
+}
+
+// maxValue returns the highest of the given values, or zero when there are none.
+func maxValue(values []float64) float64 {
+	highest := 0.0
+	for _, value := range values {
+		highest = math.Max(highest, value)
+	}
+	return highest
+}
+
+// mean returns the average of the given values, or zero when there are none.
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of the given values.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,114 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMetricPeriod(t *testing.T) {
+	assert.Equal(t, int32(300), metricPeriod(24*time.Hour))
+	assert.Equal(t, int32(300), metricPeriod(63*24*time.Hour))
+	assert.Equal(t, int32(3600), metricPeriod(90*24*time.Hour))
+}
+
+func TestMetricValues(t *testing.T) {
+	mockClient := new(mocks.MockCloudWatchClient)
+	query := types.MetricDataQuery{Id: aws.String("query")}
+
+	// Test multiple pages
+	mockClient.On("GetMetricData", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Values: []float64{1, 2}}},
+		NextToken:         aws.String("next"),
+	}, nil).Once()
+	mockClient.On("GetMetricData", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Values: []float64{3}}},
+	}, nil).Once()
+
+	values, err := metricValues(context.TODO(), mockClient, query, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3}, values)
+
+	// Test error
+	expectedError := errors.New("test error")
+	mockClient.On("GetMetricData", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	values, err = metricValues(context.TODO(), mockClient, query, time.Hour)
+	assert.Equal(t, expectedError, err)
+	assert.Nil(t, values)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestMetricTotals(t *testing.T) {
+	mockClient := new(mocks.MockCloudWatchClient)
+	queries := make([]types.MetricDataQuery, maxMetricDataQueries+1)
+	now := time.Now()
+
+	// Test batches of queries and multiple pages
+	mockClient.On("GetMetricData", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricDataInput) bool {
+		return len(input.MetricDataQueries) == maxMetricDataQueries
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Id: aws.String("a"), Timestamps: []time.Time{now}, Values: []float64{1}}},
+		NextToken:         aws.String("next"),
+	}, nil).Once()
+	mockClient.On("GetMetricData", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricDataInput) bool {
+		return len(input.MetricDataQueries) == maxMetricDataQueries
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Id: aws.String("b"), Timestamps: []time.Time{now}, Values: []float64{2}}},
+	}, nil).Once()
+	mockClient.On("GetMetricData", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricDataInput) bool {
+		return len(input.MetricDataQueries) == 1
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Id: aws.String("c"), Timestamps: []time.Time{now, now.Add(-time.Hour)}, Values: []float64{3, 4}}},
+	}, nil).Once()
+
+	weights := map[string]float64{"a": 1, "b": 10, "c": 100}
+	values, err := metricTotals(context.TODO(), mockClient, queries, time.Hour, func(id string, datapoint float64) float64 {
+		return weights[id] * datapoint
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []float64{321, 400}, values)
+
+	// Test error
+	expectedError := errors.New("test error")
+	mockClient.On("GetMetricData", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	values, err = metricTotals(context.TODO(), mockClient, queries[:1], time.Hour, func(_ string, datapoint float64) float64 {
+		return datapoint
+	})
+	assert.Equal(t, expectedError, err)
+	assert.Nil(t, values)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestMaxValue(t *testing.T) {
+	assert.Equal(t, 0.0, maxValue(nil))
+	assert.Equal(t, 7.0, maxValue([]float64{3, 7, 5}))
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, 0.0, percentile(nil, 95))
+	assert.Equal(t, 4.0, percentile([]float64{4}, 95))
+	assert.Equal(t, 95.0, percentile(func() []float64 {
+		values := []float64{}
+		for i := 100; i >= 1; i-- {
+			values = append(values, float64(i))
+		}
+		return values
+	}(), 95))
+}
+
+func TestMean(t *testing.T) {
+	assert.Equal(t, 0.0, mean(nil))
+	assert.Equal(t, 2.5, mean([]float64{1, 2, 3, 4}))
+}
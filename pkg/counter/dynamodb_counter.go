@@ -1,6 +1,12 @@
 package counter
 
-import "aws-resource-discovery/pkg/interfaces"
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
 
 // DynamoDbCounter is a counter for DynamoDB tables.
 type DynamoDbCounter struct {
@@ -15,8 +21,34 @@ func NewDynamoDbCounter(client interfaces.CloudControlClient) *DynamoDbCounter {
 			Result:   interfaces.CounterResult{CounterClass: "AWS::DynamoDB::Table"},
 			TypeName: "AWS::DynamoDB::Table",
 			PermissionSuggestionFunc: func() string {
-				return "\nTo scan DynamoDB tables, the provided credentials must have the following permissions:\n- dynamodb:ListTables\n- dynamodb:ListGlobalTables\n"
+				return permissionSuggestion("DynamoDB tables", "AWS::DynamoDB::Table")
 			},
 		},
 	}
 }
+
+// NewNativeDynamoDbCounter creates a new DynamoDbCounter that lists tables with the DynamoDB API.
+func NewNativeDynamoDbCounter(client interfaces.DynamoDBClient) *DynamoDbCounter {
+	counter := NewDynamoDbCounter(nil)
+	counter.NativeListFunc = func() ([]interfaces.ResourceRecord, error) {
+		return dynamoDbTables(client)
+	}
+	return counter
+}
+
+// dynamoDbTables lists the DynamoDB tables.
+func dynamoDbTables(client interfaces.DynamoDBClient) ([]interfaces.ResourceRecord, error) {
+	input := &dynamodb.ListTablesInput{Limit: aws.Int32(100)}
+	resources := []interfaces.ResourceRecord{}
+	paginator := dynamodb.NewListTablesPaginator(client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, tableName := range output.TableNames {
+			resources = append(resources, interfaces.ResourceRecord{Identifier: tableName})
+		}
+	}
+	return resources, nil
+}
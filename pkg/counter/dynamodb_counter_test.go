@@ -6,8 +6,10 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -40,7 +42,7 @@ func TestDynamoDbCounter_Call(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestDynamoDbCounter_paginatedCount(t *testing.T) {
+func TestDynamoDbCounter_listResources(t *testing.T) {
 	mockClient := new(mocks.MockCloudControlClient)
 	counter := NewDynamoDbCounter(mockClient)
 
@@ -49,8 +51,9 @@ func TestDynamoDbCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 5),
 	}, nil).Once()
 
-	count := counter.paginatedCount()
-	assert.Equal(t, 5, count)
+	resources, err := counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
 
 	// Test multiple pages
 	mockClient.On("ListResources", mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
@@ -61,8 +64,9 @@ func TestDynamoDbCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 3),
 	}, nil).Once()
 
-	count = counter.paginatedCount()
-	assert.Equal(t, 8, count)
+	resources, err = counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 8)
 
 	mockClient.AssertExpectations(t)
 }
@@ -97,3 +101,39 @@ func TestDynamoDbCounter_GetResult(t *testing.T) {
 	assert.Equal(t, 5, result.Count)
 	assert.Equal(t, "AWS::DynamoDB::Table", result.CounterClass)
 }
+
+func TestNativeDynamoDbCounter_Call(t *testing.T) {
+	mockClient := new(mocks.MockDynamoDBClient)
+	counter := NewNativeDynamoDbCounter(mockClient)
+
+	// Test multiple pages
+	mockClient.On("ListTables", mock.Anything, mock.MatchedBy(func(input *dynamodb.ListTablesInput) bool {
+		return input.ExclusiveStartTableName == nil
+	})).Return(&dynamodb.ListTablesOutput{
+		TableNames:             make([]string, 5),
+		LastEvaluatedTableName: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("ListTables", mock.Anything, mock.MatchedBy(func(input *dynamodb.ListTablesInput) bool {
+		return aws.ToString(input.ExclusiveStartTableName) == "next"
+	})).Return(&dynamodb.ListTablesOutput{
+		TableNames: make([]string, 3),
+	}, nil).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 8, counter.Result.Count)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, SourceNative, counter.Result.Source)
+
+	// Test call with error
+	expectedError := errors.New("test error")
+	mockClient.On("ListTables", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 0, counter.Result.Count)
+	assert.ErrorIs(t, counter.Result.Error, expectedError)
+	assert.Contains(t, counter.Result.PermissionSuggestion, "dynamodb:ListTables")
+
+	mockClient.AssertExpectations(t)
+}
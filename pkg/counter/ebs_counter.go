@@ -1,6 +1,12 @@
 package counter
 
-import "aws-resource-discovery/pkg/interfaces"
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
 
 // EbsCounter is a counter for EBS volumes.
 type EbsCounter struct {
@@ -15,8 +21,38 @@ func NewEbsCounter(client interfaces.CloudControlClient) *EbsCounter {
 			Result:   interfaces.CounterResult{CounterClass: "AWS::EC2::Volume"},
 			TypeName: "AWS::EC2::Volume",
 			PermissionSuggestionFunc: func() string {
-				return "\nTo scan EBS volumes, the provided credentials must have the following permissions:\n- ec2:DescribeVolumes\n"
+				return permissionSuggestion("EBS volumes", "AWS::EC2::Volume")
 			},
 		},
 	}
 }
+
+// NewNativeEbsCounter creates a new EbsCounter that lists volumes with the EC2 API.
+func NewNativeEbsCounter(client interfaces.EC2Client) *EbsCounter {
+	counter := NewEbsCounter(nil)
+	counter.NativeListFunc = func() ([]interfaces.ResourceRecord, error) {
+		return ebsVolumes(client)
+	}
+	return counter
+}
+
+// ebsVolumes lists the EBS volumes.
+func ebsVolumes(client interfaces.EC2Client) ([]interfaces.ResourceRecord, error) {
+	input := &ec2.DescribeVolumesInput{MaxResults: aws.Int32(500)}
+	resources := []interfaces.ResourceRecord{}
+	paginator := ec2.NewDescribeVolumesPaginator(client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range output.Volumes {
+			resources = append(resources, interfaces.ResourceRecord{
+				Identifier: aws.ToString(volume.VolumeId),
+				State:      string(volume.State),
+				Tags:       ec2Tags(volume.Tags),
+			})
+		}
+	}
+	return resources, nil
+}
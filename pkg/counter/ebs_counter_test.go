@@ -6,8 +6,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -40,7 +43,7 @@ func TestEbsCounter_Call(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestEbsCounter_paginatedCount(t *testing.T) {
+func TestEbsCounter_listResources(t *testing.T) {
 	mockClient := new(mocks.MockCloudControlClient)
 	counter := NewEbsCounter(mockClient)
 
@@ -49,8 +52,9 @@ func TestEbsCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 5),
 	}, nil).Once()
 
-	count := counter.paginatedCount()
-	assert.Equal(t, 5, count)
+	resources, err := counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
 
 	// Test multiple pages
 	mockClient.On("ListResources", mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
@@ -61,8 +65,9 @@ func TestEbsCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 3),
 	}, nil).Once()
 
-	count = counter.paginatedCount()
-	assert.Equal(t, 8, count)
+	resources, err = counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 8)
 
 	mockClient.AssertExpectations(t)
 }
@@ -97,3 +102,39 @@ func TestEbsCounter_GetResult(t *testing.T) {
 	assert.Equal(t, 5, result.Count)
 	assert.Equal(t, "AWS::EC2::Volume", result.CounterClass)
 }
+
+func TestNativeEbsCounter_Call(t *testing.T) {
+	mockClient := new(mocks.MockEC2Client)
+	counter := NewNativeEbsCounter(mockClient)
+
+	// Test multiple pages
+	mockClient.On("DescribeVolumes", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeVolumesInput) bool {
+		return input.NextToken == nil
+	})).Return(&ec2.DescribeVolumesOutput{
+		Volumes:   make([]ec2_types.Volume, 5),
+		NextToken: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("DescribeVolumes", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeVolumesInput) bool {
+		return aws.ToString(input.NextToken) == "next"
+	})).Return(&ec2.DescribeVolumesOutput{
+		Volumes: make([]ec2_types.Volume, 3),
+	}, nil).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 8, counter.Result.Count)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, SourceNative, counter.Result.Source)
+
+	// Test call with error
+	expectedError := errors.New("test error")
+	mockClient.On("DescribeVolumes", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 0, counter.Result.Count)
+	assert.ErrorIs(t, counter.Result.Error, expectedError)
+	assert.Contains(t, counter.Result.PermissionSuggestion, "ec2:DescribeVolumes")
+
+	mockClient.AssertExpectations(t)
+}
@@ -1,6 +1,13 @@
 package counter
 
-import "aws-resource-discovery/pkg/interfaces"
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
 
 // Ec2Counter is a counter for EC2 instances.
 type Ec2Counter struct {
@@ -15,8 +22,65 @@ func NewEc2Counter(client interfaces.CloudControlClient) *Ec2Counter {
 			Result:   interfaces.CounterResult{CounterClass: "AWS::EC2::Instance"},
 			TypeName: "AWS::EC2::Instance",
 			PermissionSuggestionFunc: func() string {
-				return "\nTo scan EC2 instances, the provided credentials must have the following permissions:\n- ec2:DescribeInstances\n"
+				return permissionSuggestion("EC2 instances", "AWS::EC2::Instance")
+			},
+		},
+	}
+}
+
+// NewNativeEc2Counter creates a new Ec2Counter that lists instances with the EC2 API.
+func NewNativeEc2Counter(client interfaces.EC2Client) *Ec2Counter {
+	counter := NewEc2Counter(nil)
+	counter.NativeListFunc = func() ([]interfaces.ResourceRecord, error) {
+		return ec2Instances(client)
+	}
+	return counter
+}
+
+// ec2Instances lists the instances that have not been terminated.
+func ec2Instances(client interfaces.EC2Client) ([]interfaces.ResourceRecord, error) {
+	input := &ec2.DescribeInstancesInput{
+		MaxResults: aws.Int32(1000),
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []string{"pending", "running", "shutting-down", "stopping", "stopped"},
 			},
 		},
 	}
+	resources := []interfaces.ResourceRecord{}
+	paginator := ec2.NewDescribeInstancesPaginator(client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				resources = append(resources, ec2InstanceRecord(instance))
+			}
+		}
+	}
+	return resources, nil
+}
+
+// ec2InstanceRecord describes an instance with its state and tags.
+func ec2InstanceRecord(instance types.Instance) interfaces.ResourceRecord {
+	record := interfaces.ResourceRecord{
+		Identifier: aws.ToString(instance.InstanceId),
+		Tags:       ec2Tags(instance.Tags),
+	}
+	if instance.State != nil {
+		record.State = string(instance.State.Name)
+	}
+	return record
+}
+
+// ec2Tags converts EC2 tags to a map.
+func ec2Tags(tags []types.Tag) map[string]string {
+	converted := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		converted[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return converted
 }
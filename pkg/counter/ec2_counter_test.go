@@ -6,8 +6,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -40,7 +43,7 @@ func TestEc2Counter_Call(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestEc2Counter_paginatedCount(t *testing.T) {
+func TestEc2Counter_listResources(t *testing.T) {
 	mockClient := new(mocks.MockCloudControlClient)
 	counter := NewEc2Counter(mockClient)
 
@@ -49,8 +52,9 @@ func TestEc2Counter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 5),
 	}, nil).Once()
 
-	count := counter.paginatedCount()
-	assert.Equal(t, 5, count)
+	resources, err := counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
 
 	// Test multiple pages
 	mockClient.On("ListResources", mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
@@ -61,8 +65,9 @@ func TestEc2Counter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 3),
 	}, nil).Once()
 
-	count = counter.paginatedCount()
-	assert.Equal(t, 8, count)
+	resources, err = counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 8)
 
 	mockClient.AssertExpectations(t)
 }
@@ -97,3 +102,53 @@ func TestEc2Counter_GetResult(t *testing.T) {
 	assert.Equal(t, 5, result.Count)
 	assert.Equal(t, "AWS::EC2::Instance", result.CounterClass)
 }
+
+func TestNativeEc2Counter_Call(t *testing.T) {
+	mockClient := new(mocks.MockEC2Client)
+	counter := NewNativeEc2Counter(mockClient)
+
+	// Terminated instances are filtered out by the request
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return aws.ToInt32(input.MaxResults) == 1000 && len(input.Filters) == 1 && input.NextToken == nil
+	})).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []ec2_types.Reservation{{Instances: make([]ec2_types.Instance, 2)}, {Instances: make([]ec2_types.Instance, 1)}},
+		NextToken:    aws.String("next"),
+	}, nil).Once()
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return aws.ToString(input.NextToken) == "next"
+	})).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []ec2_types.Reservation{{Instances: make([]ec2_types.Instance, 4)}},
+	}, nil).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 7, counter.Result.Count)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, SourceNative, counter.Result.Source)
+
+	// Test call with error
+	expectedError := errors.New("test error")
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 0, counter.Result.Count)
+	assert.ErrorIs(t, counter.Result.Error, expectedError)
+	assert.Equal(t, "\nTo scan EC2 instances, the provided credentials must have the following permissions:\n- ec2:DescribeInstances\n", counter.Result.PermissionSuggestion)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestEc2InstanceRecord(t *testing.T) {
+	record := ec2InstanceRecord(ec2_types.Instance{
+		InstanceId: aws.String("i-0123"),
+		State:      &ec2_types.InstanceState{Name: ec2_types.InstanceStateNameRunning},
+		Tags:       []ec2_types.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+	})
+
+	assert.Equal(t, interfaces.ResourceRecord{
+		Identifier: "i-0123",
+		State:      "running",
+		Tags:       map[string]string{"env": "prod"},
+	}, record)
+}
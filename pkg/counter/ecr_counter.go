@@ -6,13 +6,15 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
 )
 
 // EcrCounter is a counter for ECR repositories.
 type EcrCounter struct {
-	Client interfaces.ECRClient
-	Result interfaces.CounterResult
+	Client    interfaces.ECRClient
+	Result    interfaces.CounterResult
+	resources []interfaces.ResourceRecord
 }
 
 // NewEcrCounter creates a new EcrCounter.
@@ -27,6 +29,7 @@ func NewEcrCounter(client interfaces.ECRClient) *EcrCounter {
 func (c *EcrCounter) Call() {
 	count, err := c.ecrCount()
 	c.Result = c.formatResult(count, err)
+	c.Result.Resources = c.resources
 	if err != nil {
 		log.Printf("Error counting AWS::ECR::Repository: %v", err)
 	}
@@ -36,6 +39,7 @@ func (c *EcrCounter) Call() {
 func (c *EcrCounter) ecrCount() (int, error) {
 	input := &ecr.DescribeRepositoriesInput{}
 	totalCount := 0
+	c.resources = nil
 
 	for {
 		result, err := c.Client.DescribeRepositories(context.TODO(), input)
@@ -73,6 +77,9 @@ func (c *EcrCounter) countImagesInRepository(repositoryName *string) (int, error
 			return 0, fmt.Errorf("failed to list images in repository %s: %w", *repositoryName, err)
 		}
 		imageCount += len(result.ImageIds)
+		for _, image := range result.ImageIds {
+			c.resources = append(c.resources, interfaces.ResourceRecord{Identifier: imageIdentifier(aws.ToString(repositoryName), image.ImageTag, image.ImageDigest)})
+		}
 		if result.NextToken == nil {
 			break
 		}
@@ -88,6 +95,7 @@ func (c *EcrCounter) formatResult(count int, err error) interfaces.CounterResult
 		Count:        count,
 		CounterClass: "AWS::ECR::Repository",
 		Error:        err,
+		Source:       SourceNative,
 	}
 	if err != nil {
 		result.PermissionSuggestion = c.permissionSuggestion()
@@ -97,14 +105,18 @@ func (c *EcrCounter) formatResult(count int, err error) interfaces.CounterResult
 
 // permissionSuggestion returns the permissions needed for counting ECR repositories.
 func (c *EcrCounter) permissionSuggestion() string {
-	return `
-To scan ECR repositories, the provided credentials must have the following permissions:
-  - ecr:DescribeRepositories
-  - ecr:ListImages
-`
+	return permissionSuggestion("ECR repositories", "AWS::ECR::Repository")
 }
 
 // GetResult returns the counter result.
 func (c *EcrCounter) GetResult() interfaces.CounterResult {
 	return c.Result
 }
+
+// imageIdentifier identifies an image by its tag, or by its digest when untagged.
+func imageIdentifier(repositoryName string, tag, digest *string) string {
+	if tag != nil {
+		return repositoryName + ":" + *tag
+	}
+	return repositoryName + "@" + aws.ToString(digest)
+}
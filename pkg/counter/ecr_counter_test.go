@@ -52,8 +52,8 @@ func TestEcrCounter_Call(t *testing.T) {
 
 	assert.Equal(t, `
 To scan ECR repositories, the provided credentials must have the following permissions:
-  - ecr:DescribeRepositories
-  - ecr:ListImages
+- ecr:DescribeRepositories
+- ecr:ListImages
 `, counter.Result.PermissionSuggestion)
 
 	mockClient.AssertExpectations(t)
@@ -82,6 +82,7 @@ func TestEcrCounter_ecrCount(t *testing.T) {
 	count, err := counter.ecrCount()
 	assert.Equal(t, 2, count)
 	assert.Nil(t, err)
+	assert.Equal(t, []interfaces.ResourceRecord{{Identifier: "repo1:image1"}, {Identifier: "repo1:image2"}}, counter.resources)
 
 	mockClient.AssertExpectations(t)
 }
@@ -124,8 +125,8 @@ func TestEcrCounter_formatResult(t *testing.T) {
 	assert.Equal(t, err, result.Error)
 	assert.Equal(t, `
 To scan ECR repositories, the provided credentials must have the following permissions:
-  - ecr:DescribeRepositories
-  - ecr:ListImages
+- ecr:DescribeRepositories
+- ecr:ListImages
 `, result.PermissionSuggestion)
 }
 
@@ -6,13 +6,15 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecrpublic"
 )
 
 // EcrPublicCounter is a counter for ECR public repositories.
 type EcrPublicCounter struct {
-	Client interfaces.ECRPublicClient
-	Result interfaces.CounterResult
+	Client    interfaces.ECRPublicClient
+	Result    interfaces.CounterResult
+	resources []interfaces.ResourceRecord
 }
 
 // NewEcrPublicCounter creates a new EcrPublicCounter.
@@ -27,6 +29,7 @@ func NewEcrPublicCounter(client interfaces.ECRPublicClient) *EcrPublicCounter {
 func (c *EcrPublicCounter) Call() {
 	count, err := c.ecrPublicCount()
 	c.Result = c.formatResult(count, err)
+	c.Result.Resources = c.resources
 	if err != nil {
 		log.Printf("Error counting AWS::ECR::PublicRepository: %v", err)
 	}
@@ -36,6 +39,7 @@ func (c *EcrPublicCounter) Call() {
 func (c *EcrPublicCounter) ecrPublicCount() (int, error) {
 	input := &ecrpublic.DescribeRepositoriesInput{}
 	totalCount := 0
+	c.resources = nil
 
 	for {
 		result, err := c.Client.DescribeRepositories(context.TODO(), input)
@@ -73,6 +77,9 @@ func (c *EcrPublicCounter) countImagesInRepository(repositoryName *string) (int,
 			return 0, fmt.Errorf("failed to describe images in repository %s: %w", *repositoryName, err)
 		}
 		imageCount += len(result.ImageDetails)
+		for _, image := range result.ImageDetails {
+			c.resources = append(c.resources, interfaces.ResourceRecord{Identifier: imageIdentifier(aws.ToString(repositoryName), nil, image.ImageDigest)})
+		}
 		if result.NextToken == nil {
 			break
 		}
@@ -88,6 +95,7 @@ func (c *EcrPublicCounter) formatResult(count int, err error) interfaces.Counter
 		Count:        count,
 		CounterClass: "AWS::ECR::PublicRepository",
 		Error:        err,
+		Source:       SourceNative,
 	}
 	if err != nil {
 		result.PermissionSuggestion = c.permissionSuggestion()
@@ -97,11 +105,7 @@ func (c *EcrPublicCounter) formatResult(count int, err error) interfaces.Counter
 
 // permissionSuggestion returns the permissions needed for counting ECR public repositories.
 func (c *EcrPublicCounter) permissionSuggestion() string {
-	return `
-To scan public ECR repositories, the provided credentials must have the following permissions:
-  - ecr-public:DescribeRepositories
-  - ecr-public:DescribeImages
-`
+	return permissionSuggestion("public ECR repositories", "AWS::ECR::PublicRepository")
 }
 
 // GetResult returns the counter result.
@@ -51,8 +51,8 @@ func TestEcrPublicCounter_Call(t *testing.T) {
 	assert.Contains(t, counter.Result.Error.Error(), "failed to list public ECR repositories: test error")
 	assert.Equal(t, `
 To scan public ECR repositories, the provided credentials must have the following permissions:
-  - ecr-public:DescribeRepositories
-  - ecr-public:DescribeImages
+- ecr-public:DescribeRepositories
+- ecr-public:DescribeImages
 `, counter.Result.PermissionSuggestion)
 
 	mockClient.AssertExpectations(t)
@@ -103,8 +103,8 @@ func TestEcrPublicCounter_formatResult(t *testing.T) {
 	assert.Equal(t, err, result.Error)
 	assert.Equal(t, `
 To scan public ECR repositories, the provided credentials must have the following permissions:
-  - ecr-public:DescribeRepositories
-  - ecr-public:DescribeImages
+- ecr-public:DescribeRepositories
+- ecr-public:DescribeImages
 `, result.PermissionSuggestion)
 }
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
@@ -14,6 +15,7 @@ import (
 type EcsCounter struct {
 	ECSClient interfaces.ECSClient
 	Result    interfaces.CounterResult
+	resources []interfaces.ResourceRecord
 }
 
 // NewEcsCounter creates a new EcsCounter.
@@ -30,6 +32,7 @@ func NewEcsCounter(client interfaces.ECSClient) *EcsCounter {
 func (c *EcsCounter) Call() {
 	count, err := c.ecsCount()
 	c.Result = c.formatResult(count, err)
+	c.Result.Resources = c.resources
 	if err != nil {
 		log.Printf("Error counting AWS::ECS::Cluster: %v", err)
 	}
@@ -38,6 +41,7 @@ func (c *EcsCounter) Call() {
 // ecsCount counts the number of running ECS containers.
 func (c *EcsCounter) ecsCount() (int, error) {
 	count := 0
+	c.resources = nil
 	clusters, err := c.listClusters()
 	if err != nil {
 		return 0, fmt.Errorf("failed to list ECS clusters: %w", err)
@@ -56,7 +60,17 @@ func (c *EcsCounter) ecsCount() (int, error) {
 				if err != nil {
 					return 0, fmt.Errorf("failed to describe task definition for %s: %w", *deployment.TaskDefinition, err)
 				}
-				count += int(deployment.RunningCount) * len(task.TaskDefinition.ContainerDefinitions)
+				containers := int(deployment.RunningCount) * len(task.TaskDefinition.ContainerDefinitions)
+				count += containers
+				if containers > 0 {
+					c.resources = append(c.resources, interfaces.ResourceRecord{
+						Identifier: aws.ToString(deployment.Id),
+						Arn:        aws.ToString(service.ServiceArn),
+						State:      aws.ToString(deployment.Status),
+						Count:      containers,
+						Tasks:      int(deployment.RunningCount),
+					})
+				}
 			}
 		}
 	}
@@ -108,6 +122,7 @@ func (c *EcsCounter) formatResult(count int, err error) interfaces.CounterResult
 		Count:        count,
 		CounterClass: "AWS::ECS::Cluster",
 		Error:        err,
+		Source:       SourceNative,
 	}
 	if err != nil {
 		result.PermissionSuggestion = c.permissionSuggestion()
@@ -117,13 +132,7 @@ func (c *EcsCounter) formatResult(count int, err error) interfaces.CounterResult
 
 // permissionSuggestion returns the permissions needed for counting ECS containers.
 func (c *EcsCounter) permissionSuggestion() string {
-	return `
-To scan ECS containers, the provided credentials must have the following permissions:
-- ecs:ListClusters
-- ecs:ListServices
-- ecs:DescribeServices
-- ecs:DescribeTaskDefinition
-`
+	return permissionSuggestion("ECS containers", "AWS::ECS::Cluster")
 }
 
 // GetResult returns the counter result.
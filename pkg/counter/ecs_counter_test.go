@@ -120,6 +120,9 @@ func TestEcsCounter_ecsCount(t *testing.T) {
 	count, err := counter.ecsCount()
 	assert.Equal(t, 4, count)
 	assert.Nil(t, err)
+	assert.Len(t, counter.resources, 1)
+	assert.Equal(t, 4, counter.resources[0].Count)
+	assert.Equal(t, 2, counter.resources[0].Tasks)
 
 	mockClient.AssertExpectations(t)
 }
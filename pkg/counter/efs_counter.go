@@ -1,6 +1,12 @@
 package counter
 
-import "aws-resource-discovery/pkg/interfaces"
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+)
 
 // EfsCounter is a counter for EFS file systems.
 type EfsCounter struct {
@@ -15,8 +21,43 @@ func NewEfsCounter(client interfaces.CloudControlClient) *EfsCounter {
 			Result:   interfaces.CounterResult{CounterClass: "AWS::EFS::FileSystem"},
 			TypeName: "AWS::EFS::FileSystem",
 			PermissionSuggestionFunc: func() string {
-				return "\nTo scan EFS file systems, the provided credentials must have the following permissions:\n- elasticfilesystem:DescribeFileSystems\n"
+				return permissionSuggestion("EFS file systems", "AWS::EFS::FileSystem")
 			},
 		},
 	}
 }
+
+// NewNativeEfsCounter creates a new EfsCounter that lists file systems with the EFS API.
+func NewNativeEfsCounter(client interfaces.EFSClient) *EfsCounter {
+	counter := NewEfsCounter(nil)
+	counter.NativeListFunc = func() ([]interfaces.ResourceRecord, error) {
+		return efsFileSystems(client)
+	}
+	return counter
+}
+
+// efsFileSystems lists the EFS file systems.
+func efsFileSystems(client interfaces.EFSClient) ([]interfaces.ResourceRecord, error) {
+	input := &efs.DescribeFileSystemsInput{MaxItems: aws.Int32(100)}
+	resources := []interfaces.ResourceRecord{}
+	paginator := efs.NewDescribeFileSystemsPaginator(client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, fileSystem := range output.FileSystems {
+			tags := make(map[string]string, len(fileSystem.Tags))
+			for _, tag := range fileSystem.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			resources = append(resources, interfaces.ResourceRecord{
+				Identifier: aws.ToString(fileSystem.FileSystemId),
+				Arn:        aws.ToString(fileSystem.FileSystemArn),
+				State:      string(fileSystem.LifeCycleState),
+				Tags:       tags,
+			})
+		}
+	}
+	return resources, nil
+}
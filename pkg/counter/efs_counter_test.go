@@ -6,8 +6,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol/types"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	efs_types "github.com/aws/aws-sdk-go-v2/service/efs/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -40,7 +43,7 @@ func TestEfsCounter_Call(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestEfsCounter_paginatedCount(t *testing.T) {
+func TestEfsCounter_listResources(t *testing.T) {
 	mockClient := new(mocks.MockCloudControlClient)
 	counter := NewEfsCounter(mockClient)
 
@@ -49,8 +52,9 @@ func TestEfsCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 5),
 	}, nil).Once()
 
-	count := counter.paginatedCount()
-	assert.Equal(t, 5, count)
+	resources, err := counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
 
 	// Test multiple pages
 	mockClient.On("ListResources", mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
@@ -61,8 +65,9 @@ func TestEfsCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 3),
 	}, nil).Once()
 
-	count = counter.paginatedCount()
-	assert.Equal(t, 8, count)
+	resources, err = counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 8)
 
 	mockClient.AssertExpectations(t)
 }
@@ -97,3 +102,39 @@ func TestEfsCounter_GetResult(t *testing.T) {
 	assert.Equal(t, 5, result.Count)
 	assert.Equal(t, "AWS::EFS::FileSystem", result.CounterClass)
 }
+
+func TestNativeEfsCounter_Call(t *testing.T) {
+	mockClient := new(mocks.MockEFSClient)
+	counter := NewNativeEfsCounter(mockClient)
+
+	// Test multiple pages
+	mockClient.On("DescribeFileSystems", mock.Anything, mock.MatchedBy(func(input *efs.DescribeFileSystemsInput) bool {
+		return input.Marker == nil
+	})).Return(&efs.DescribeFileSystemsOutput{
+		FileSystems: make([]efs_types.FileSystemDescription, 5),
+		NextMarker:  aws.String("next"),
+	}, nil).Once()
+	mockClient.On("DescribeFileSystems", mock.Anything, mock.MatchedBy(func(input *efs.DescribeFileSystemsInput) bool {
+		return aws.ToString(input.Marker) == "next"
+	})).Return(&efs.DescribeFileSystemsOutput{
+		FileSystems: make([]efs_types.FileSystemDescription, 3),
+	}, nil).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 8, counter.Result.Count)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, SourceNative, counter.Result.Source)
+
+	// Test call with error
+	expectedError := errors.New("test error")
+	mockClient.On("DescribeFileSystems", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 0, counter.Result.Count)
+	assert.ErrorIs(t, counter.Result.Error, expectedError)
+	assert.Contains(t, counter.Result.PermissionSuggestion, "elasticfilesystem:DescribeFileSystems")
+
+	mockClient.AssertExpectations(t)
+}
@@ -17,6 +17,7 @@ type EksCounter struct {
 	EKSClient interfaces.EKSClient
 	EC2Client interfaces.EC2Client
 	Result    interfaces.CounterResult
+	resources []interfaces.ResourceRecord
 }
 
 // NewEksCounter creates a new EksCounter.
@@ -34,6 +35,7 @@ func NewEksCounter(eksClient interfaces.EKSClient, ec2Client interfaces.EC2Clien
 func (c *EksCounter) Call() {
 	count, err := c.eksCount()
 	c.Result = c.formatResult(count, err)
+	c.Result.Resources = c.resources
 	if err != nil {
 		log.Printf("Error counting AWS::EKS::Cluster: %v", err)
 	}
@@ -41,6 +43,7 @@ func (c *EksCounter) Call() {
 
 // eksCount counts the number of resources associated with EKS clusters.
 func (c *EksCounter) eksCount() (int, error) {
+	c.resources = nil
 	clusters, err := c.listClusters()
 	if err != nil {
 		return 0, fmt.Errorf("failed to list EKS clusters: %w", err)
@@ -99,6 +102,9 @@ func (c *EksCounter) countInstancesForCluster(clusterName string) (int, error) {
 
 		for _, reservation := range output.Reservations {
 			totalCount += len(reservation.Instances)
+			for _, instance := range reservation.Instances {
+				c.resources = append(c.resources, ec2InstanceRecord(instance))
+			}
 		}
 	}
 
@@ -111,6 +117,7 @@ func (c *EksCounter) formatResult(count int, err error) interfaces.CounterResult
 		Count:        count,
 		CounterClass: "AWS::EKS::Cluster",
 		Error:        err,
+		Source:       SourceNative,
 	}
 	if err != nil {
 		result.PermissionSuggestion = c.permissionSuggestion()
@@ -120,11 +127,7 @@ func (c *EksCounter) formatResult(count int, err error) interfaces.CounterResult
 
 // permissionSuggestion returns the permissions needed for counting EKS clusters.
 func (c *EksCounter) permissionSuggestion() string {
-	return `
-To scan EKS clusters, the provided credentials must have the following permissions:
-- eks:ListClusters
-- ec2:DescribeInstances
-`
+	return permissionSuggestion("EKS clusters", "AWS::EKS::Cluster")
 }
 
 // GetResult returns the counter result.
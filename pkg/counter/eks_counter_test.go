@@ -86,6 +86,8 @@ func TestEksCounter_eksCount(t *testing.T) {
 	count, err := counter.eksCount()
 	assert.Equal(t, 1, count)
 	assert.Nil(t, err)
+	assert.Len(t, counter.resources, 1)
+	assert.Equal(t, "i-1234567890abcdef0", counter.resources[0].Identifier)
 
 	mockEKSClient.AssertExpectations(t)
 	mockEC2Client.AssertExpectations(t)
@@ -0,0 +1,176 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// historySource reads the history of a counter class over the lookback window,
+// in the unit of its count. It receives the current result, whose resources
+// some sources need to convert their metric into that unit.
+type historySource func(client interfaces.CloudWatchClient, result interfaces.CounterResult, lookback time.Duration) ([]float64, error)
+
+// historicalMetrics maps counter classes to the source of their history.
+var historicalMetrics = map[string]historySource{
+	// EKS worker nodes, published by Container Insights.
+	"AWS::EKS::Cluster":     expressionHistory(`SUM(SEARCH('{ContainerInsights,ClusterName} MetricName="cluster_node_count"', 'Maximum', %d))`),
+	"AWS::ECS::Cluster":     ecsContainerHistory,
+	"AWS::Lambda::Function": lambdaFunctionHistory,
+}
+
+// expressionHistory reads the history from a CloudWatch metric math expression
+// that is already in the unit of the count. The %d verb receives the period in
+// seconds.
+func expressionHistory(expression string) historySource {
+	return func(client interfaces.CloudWatchClient, _ interfaces.CounterResult, lookback time.Duration) ([]float64, error) {
+		query := types.MetricDataQuery{
+			Id:         aws.String("history"),
+			Expression: aws.String(fmt.Sprintf(expression, metricPeriod(lookback))),
+		}
+		return metricValues(context.TODO(), client, query, lookback)
+	}
+}
+
+// ecsContainerHistory reads the running tasks of every counted ECS service,
+// published by Container Insights, and converts them into containers with the
+// containers per task of the service's current deployments.
+func ecsContainerHistory(client interfaces.CloudWatchClient, result interfaces.CounterResult, lookback time.Duration) ([]float64, error) {
+	type service struct{ containers, tasks int }
+	services := map[string]*service{}
+	for _, resource := range result.Resources {
+		if services[resource.Arn] == nil {
+			services[resource.Arn] = &service{}
+		}
+		services[resource.Arn].containers += resource.Count
+		services[resource.Arn].tasks += resource.Tasks
+	}
+
+	queries := []types.MetricDataQuery{}
+	containersPerTask := map[string]float64{}
+	for arn, counted := range services {
+		cluster, name, ok := ecsServiceNames(arn)
+		if !ok || counted.tasks == 0 {
+			continue
+		}
+		id := fmt.Sprintf("service%d", len(queries))
+		containersPerTask[id] = float64(counted.containers) / float64(counted.tasks)
+		queries = append(queries, types.MetricDataQuery{
+			Id: aws.String(id),
+			MetricStat: &types.MetricStat{
+				Metric: &types.Metric{
+					Namespace:  aws.String("ECS/ContainerInsights"),
+					MetricName: aws.String("RunningTaskCount"),
+					Dimensions: []types.Dimension{
+						{Name: aws.String("ClusterName"), Value: aws.String(cluster)},
+						{Name: aws.String("ServiceName"), Value: aws.String(name)},
+					},
+				},
+				Period: aws.Int32(metricPeriod(lookback)),
+				Stat:   aws.String("Maximum"),
+			},
+		})
+	}
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	return metricTotals(context.TODO(), client, queries, lookback, func(id string, tasks float64) float64 {
+		return tasks * containersPerTask[id]
+	})
+}
+
+// ecsServiceNames returns the cluster and service names of an ECS service ARN in
+// the long format, arn:aws:ecs:region:account:service/cluster/service.
+func ecsServiceNames(arn string) (string, string, bool) {
+	parts := strings.Split(arn, "/")
+	if len(parts) != 3 {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// lambdaFunctionHistory counts, in every period, the Lambda functions that were
+// invoked, from their Invocations metric.
+func lambdaFunctionHistory(client interfaces.CloudWatchClient, _ interfaces.CounterResult, lookback time.Duration) ([]float64, error) {
+	query := types.MetricDataQuery{
+		Id:         aws.String("invocations"),
+		Expression: aws.String(fmt.Sprintf(`SEARCH('{AWS/Lambda,FunctionName} MetricName="Invocations"', 'Sum', %d)`, metricPeriod(lookback))),
+	}
+	return metricTotals(context.TODO(), client, []types.MetricDataQuery{query}, lookback, func(_ string, invocations float64) float64 {
+		if invocations > 0 {
+			return 1
+		}
+		return 0
+	})
+}
+
+// HistoricalCounter wraps a counter and reports the maximum and average of its
+// CloudWatch metric over the lookback window alongside the current count.
+type HistoricalCounter struct {
+	Counter          interfaces.Counter
+	CloudWatchClient interfaces.CloudWatchClient
+	History          historySource
+	Lookback         time.Duration
+	Result           interfaces.CounterResult
+}
+
+// NewHistoricalCounter creates a new HistoricalCounter.
+func NewHistoricalCounter(counter interfaces.Counter, client interfaces.CloudWatchClient, history historySource, lookback time.Duration) *HistoricalCounter {
+	return &HistoricalCounter{
+		Counter:          counter,
+		CloudWatchClient: client,
+		History:          history,
+		Lookback:         lookback,
+	}
+}
+
+// WithHistory wraps every counter that has a corresponding CloudWatch metric in a HistoricalCounter.
+func WithHistory(counters []interfaces.Counter, client interfaces.CloudWatchClient, lookback time.Duration) []interfaces.Counter {
+	wrapped := make([]interfaces.Counter, 0, len(counters))
+	for _, cnt := range counters {
+		history, ok := historicalMetrics[cnt.GetResult().CounterClass]
+		if !ok {
+			wrapped = append(wrapped, cnt)
+			continue
+		}
+		wrapped = append(wrapped, NewHistoricalCounter(cnt, client, history, lookback))
+	}
+	return wrapped
+}
+
+// Call performs the counting of the wrapped counter and adds the metric history.
+// A failure to read the metric is logged and leaves the current count untouched.
+func (c *HistoricalCounter) Call() {
+	c.Counter.Call()
+	c.Result = c.Counter.GetResult()
+	if c.Result.Error != nil {
+		return
+	}
+
+	values, err := c.History(c.CloudWatchClient, c.Result, c.Lookback)
+	if err != nil {
+		log.Printf("Error reading history for %s: %v", c.Result.CounterClass, err)
+		return
+	}
+	if len(values) == 0 {
+		return
+	}
+	c.Result.Peak = max(c.Result.Count, int(math.Ceil(maxValue(values))))
+	c.Result.Average = mean(values)
+	c.Result.HasHistory = true
+}
+
+// GetResult returns the counter result.
+func (c *HistoricalCounter) GetResult() interfaces.CounterResult {
+	if c.Result.CounterClass == "" {
+		return c.Counter.GetResult()
+	}
+	return c.Result
+}
@@ -0,0 +1,165 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHistoricalCounter_Call(t *testing.T) {
+	mockCounter := new(mocks.MockCounter)
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	counter := NewHistoricalCounter(mockCounter, mockCloudWatch, historicalMetrics["AWS::EKS::Cluster"], 30*24*time.Hour)
+
+	mockCounter.On("Call").Return()
+	mockCounter.On("GetResult").Return(interfaces.CounterResult{Count: 4, CounterClass: "AWS::EKS::Cluster"})
+	mockCloudWatch.On("GetMetricData", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricDataInput) bool {
+		return aws.ToString(input.MetricDataQueries[0].Expression) ==
+			`SUM(SEARCH('{ContainerInsights,ClusterName} MetricName="cluster_node_count"', 'Maximum', 300))`
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Values: []float64{2, 4, 9}}},
+	}, nil).Once()
+
+	counter.Call()
+
+	result := counter.GetResult()
+	assert.Equal(t, 4, result.Count)
+	assert.Equal(t, 9, result.Peak)
+	assert.Equal(t, 5.0, result.Average)
+	assert.True(t, result.HasHistory)
+	assert.Nil(t, result.Error)
+
+	mockCloudWatch.AssertExpectations(t)
+}
+
+func TestHistoricalCounter_CallWithMetricError(t *testing.T) {
+	mockCounter := new(mocks.MockCounter)
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	counter := NewHistoricalCounter(mockCounter, mockCloudWatch, historicalMetrics["AWS::EKS::Cluster"], time.Hour)
+
+	mockCounter.On("Call").Return()
+	mockCounter.On("GetResult").Return(interfaces.CounterResult{Count: 4, CounterClass: "AWS::EKS::Cluster"})
+	mockCloudWatch.On("GetMetricData", mock.Anything, mock.Anything).Return(nil, errors.New("test error")).Once()
+
+	counter.Call()
+
+	result := counter.GetResult()
+	assert.Equal(t, 4, result.Count)
+	assert.Equal(t, 0, result.Peak)
+	assert.False(t, result.HasHistory)
+	assert.Nil(t, result.Error)
+}
+
+func TestHistoricalCounter_CallWithCounterError(t *testing.T) {
+	mockCounter := new(mocks.MockCounter)
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	counter := NewHistoricalCounter(mockCounter, mockCloudWatch, historicalMetrics["AWS::EKS::Cluster"], time.Hour)
+
+	expectedError := errors.New("test error")
+	mockCounter.On("Call").Return()
+	mockCounter.On("GetResult").Return(interfaces.CounterResult{CounterClass: "AWS::EKS::Cluster", Error: expectedError})
+
+	counter.Call()
+
+	assert.Equal(t, expectedError, counter.GetResult().Error)
+	mockCloudWatch.AssertNotCalled(t, "GetMetricData", mock.Anything, mock.Anything)
+}
+
+func TestWithHistory(t *testing.T) {
+	counters := []interfaces.Counter{
+		NewEc2Counter(nil),
+		NewEksCounter(nil, nil),
+		NewEcsCounter(nil),
+		NewLambdaCounter(nil),
+	}
+
+	wrapped := WithHistory(counters, nil, time.Hour)
+
+	assert.Len(t, wrapped, 4)
+	assert.IsType(t, &Ec2Counter{}, wrapped[0])
+	assert.IsType(t, &HistoricalCounter{}, wrapped[1])
+	assert.IsType(t, &HistoricalCounter{}, wrapped[2])
+	assert.IsType(t, &HistoricalCounter{}, wrapped[3])
+	assert.Equal(t, "AWS::EKS::Cluster", wrapped[1].GetResult().CounterClass)
+}
+
+func TestEcsContainerHistory(t *testing.T) {
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	result := interfaces.CounterResult{
+		CounterClass: "AWS::ECS::Cluster",
+		Resources: []interfaces.ResourceRecord{
+			{Arn: "arn:aws:ecs:us-east-1:123456789012:service/cluster1/web", Count: 6, Tasks: 3},
+			{Arn: "arn:aws:ecs:us-east-1:123456789012:service/legacy", Count: 1, Tasks: 1},
+		},
+	}
+	now := time.Now()
+
+	mockCloudWatch.On("GetMetricData", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricDataInput) bool {
+		if len(input.MetricDataQueries) != 1 {
+			return false
+		}
+		dimensions := input.MetricDataQueries[0].MetricStat.Metric.Dimensions
+		return aws.ToString(dimensions[0].Value) == "cluster1" && aws.ToString(dimensions[1].Value) == "web"
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{
+			Id:         aws.String("service0"),
+			Timestamps: []time.Time{now, now.Add(-time.Hour)},
+			Values:     []float64{3, 5},
+		}},
+	}, nil).Once()
+
+	values, err := ecsContainerHistory(mockCloudWatch, result, time.Hour)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []float64{6, 10}, values)
+	mockCloudWatch.AssertExpectations(t)
+}
+
+func TestEcsContainerHistory_WithoutServices(t *testing.T) {
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+
+	values, err := ecsContainerHistory(mockCloudWatch, interfaces.CounterResult{CounterClass: "AWS::ECS::Cluster"}, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+	mockCloudWatch.AssertNotCalled(t, "GetMetricData", mock.Anything, mock.Anything)
+}
+
+func TestLambdaFunctionHistory(t *testing.T) {
+	mockCloudWatch := new(mocks.MockCloudWatchClient)
+	now := time.Now()
+
+	mockCloudWatch.On("GetMetricData", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricDataInput) bool {
+		return aws.ToString(input.MetricDataQueries[0].Expression) ==
+			`SEARCH('{AWS/Lambda,FunctionName} MetricName="Invocations"', 'Sum', 300)`
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{
+			{Id: aws.String("invocations"), Timestamps: []time.Time{now, now.Add(-time.Hour)}, Values: []float64{120, 0}},
+			{Id: aws.String("invocations"), Timestamps: []time.Time{now}, Values: []float64{4}},
+		},
+	}, nil).Once()
+
+	values, err := lambdaFunctionHistory(mockCloudWatch, interfaces.CounterResult{}, time.Hour)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []float64{2, 0}, values)
+	mockCloudWatch.AssertExpectations(t)
+}
+
+func TestEcsServiceNames(t *testing.T) {
+	cluster, service, ok := ecsServiceNames("arn:aws:ecs:us-east-1:123456789012:service/cluster1/web")
+	assert.True(t, ok)
+	assert.Equal(t, "cluster1", cluster)
+	assert.Equal(t, "web", service)
+
+	_, _, ok = ecsServiceNames("arn:aws:ecs:us-east-1:123456789012:service/legacy")
+	assert.False(t, ok)
+}
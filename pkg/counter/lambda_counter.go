@@ -1,6 +1,12 @@
 package counter
 
-import "aws-resource-discovery/pkg/interfaces"
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
 
 // LambdaCounter is a counter for Lambda functions.
 type LambdaCounter struct {
@@ -15,8 +21,38 @@ func NewLambdaCounter(client interfaces.CloudControlClient) *LambdaCounter {
 			Result:   interfaces.CounterResult{CounterClass: "AWS::Lambda::Function"},
 			TypeName: "AWS::Lambda::Function",
 			PermissionSuggestionFunc: func() string {
-				return "\nTo scan Lambda functions, the provided credentials must have the following permissions:\n- lambda:ListFunctions\n"
+				return permissionSuggestion("Lambda functions", "AWS::Lambda::Function")
 			},
 		},
 	}
 }
+
+// NewNativeLambdaCounter creates a new LambdaCounter that lists functions with the Lambda API.
+func NewNativeLambdaCounter(client interfaces.LambdaClient) *LambdaCounter {
+	counter := NewLambdaCounter(nil)
+	counter.NativeListFunc = func() ([]interfaces.ResourceRecord, error) {
+		return lambdaFunctions(client)
+	}
+	return counter
+}
+
+// lambdaFunctions lists the Lambda functions.
+func lambdaFunctions(client interfaces.LambdaClient) ([]interfaces.ResourceRecord, error) {
+	input := &lambda.ListFunctionsInput{MaxItems: aws.Int32(50)}
+	resources := []interfaces.ResourceRecord{}
+	paginator := lambda.NewListFunctionsPaginator(client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, function := range output.Functions {
+			resources = append(resources, interfaces.ResourceRecord{
+				Identifier: aws.ToString(function.FunctionName),
+				Arn:        aws.ToString(function.FunctionArn),
+				State:      string(function.State),
+			})
+		}
+	}
+	return resources, nil
+}
@@ -8,8 +8,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambda_types "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -42,7 +45,7 @@ func TestLambdaCounter_Call(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestLambdaCounter_paginatedCount(t *testing.T) {
+func TestLambdaCounter_listResources(t *testing.T) {
 	mockClient := new(mocks.MockCloudControlClient)
 	counter := NewLambdaCounter(mockClient)
 
@@ -51,8 +54,9 @@ func TestLambdaCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 5),
 	}, nil).Once()
 
-	count := counter.paginatedCount()
-	assert.Equal(t, 5, count)
+	resources, err := counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
 
 	// Test multiple pages
 	mockClient.On("ListResources", mock.Anything, mock.Anything).Return(&cloudcontrol.ListResourcesOutput{
@@ -63,8 +67,9 @@ func TestLambdaCounter_paginatedCount(t *testing.T) {
 		ResourceDescriptions: make([]types.ResourceDescription, 3),
 	}, nil).Once()
 
-	count = counter.paginatedCount()
-	assert.Equal(t, 8, count)
+	resources, err = counter.listResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 8)
 
 	mockClient.AssertExpectations(t)
 }
@@ -99,3 +104,39 @@ func TestLambdaCounter_GetResult(t *testing.T) {
 	assert.Equal(t, 5, result.Count)
 	assert.Equal(t, "AWS::Lambda::Function", result.CounterClass)
 }
+
+func TestNativeLambdaCounter_Call(t *testing.T) {
+	mockClient := new(mocks.MockLambdaClient)
+	counter := NewNativeLambdaCounter(mockClient)
+
+	// Test multiple pages
+	mockClient.On("ListFunctions", mock.Anything, mock.MatchedBy(func(input *lambda.ListFunctionsInput) bool {
+		return input.Marker == nil
+	})).Return(&lambda.ListFunctionsOutput{
+		Functions:  make([]lambda_types.FunctionConfiguration, 5),
+		NextMarker: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("ListFunctions", mock.Anything, mock.MatchedBy(func(input *lambda.ListFunctionsInput) bool {
+		return aws.ToString(input.Marker) == "next"
+	})).Return(&lambda.ListFunctionsOutput{
+		Functions: make([]lambda_types.FunctionConfiguration, 3),
+	}, nil).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 8, counter.Result.Count)
+	assert.Nil(t, counter.Result.Error)
+	assert.Equal(t, SourceNative, counter.Result.Source)
+
+	// Test call with error
+	expectedError := errors.New("test error")
+	mockClient.On("ListFunctions", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	counter.Call()
+
+	assert.Equal(t, 0, counter.Result.Count)
+	assert.ErrorIs(t, counter.Result.Error, expectedError)
+	assert.Contains(t, counter.Result.PermissionSuggestion, "lambda:ListFunctions")
+
+	mockClient.AssertExpectations(t)
+}
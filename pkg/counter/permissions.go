@@ -0,0 +1,45 @@
+package counter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Permissions lists, by resource type, the IAM actions its counter calls. The
+// permission suggestions of the counters, the preflight checks and the
+// simulated actions are all built from it.
+var Permissions = map[string][]string{
+	"AWS::AutoScaling::AutoScalingGroup": {"autoscaling:DescribeAutoScalingGroups"},
+	"AWS::DynamoDB::Table":               {"dynamodb:ListTables", "dynamodb:ListGlobalTables"},
+	"AWS::EC2::Instance":                 {"ec2:DescribeInstances"},
+	"AWS::EC2::Volume":                   {"ec2:DescribeVolumes"},
+	"AWS::ECR::PublicRepository":         {"ecr-public:DescribeRepositories", "ecr-public:DescribeImages"},
+	"AWS::ECR::Repository":               {"ecr:DescribeRepositories", "ecr:ListImages"},
+	"AWS::ECS::Cluster":                  {"ecs:ListClusters", "ecs:ListServices", "ecs:DescribeServices", "ecs:DescribeTaskDefinition"},
+	"AWS::EFS::FileSystem":               {"elasticfilesystem:DescribeFileSystems"},
+	"AWS::EKS::Cluster":                  {"eks:ListClusters", "ec2:DescribeInstances"},
+	"AWS::Lambda::Function":              {"lambda:ListFunctions"},
+	"AWS::RDS::DBInstance":               {"rds:DescribeDBInstances"},
+	"AWS::S3::Bucket":                    {"s3:ListAllMyBuckets", "s3:GetBucketLocation"},
+}
+
+const (
+	// CloudControlPermission is needed on top of the permissions of a
+	// resource type to list it with CloudControl.
+	CloudControlPermission = "cloudformation:ListResources"
+	// TagsPermission is needed by tag filters and grouping by tag.
+	TagsPermission = "tag:GetResources"
+	// HistoryPermission is needed to read the metrics of a lookback period.
+	HistoryPermission = "cloudwatch:GetMetricData"
+)
+
+// permissionSuggestion returns the permissions needed for counting the
+// resources of a type, e.g. "EC2 instances" for AWS::EC2::Instance.
+func permissionSuggestion(resources, resourceType string, extra ...string) string {
+	var suggestion strings.Builder
+	fmt.Fprintf(&suggestion, "\nTo scan %s, the provided credentials must have the following permissions:\n", resources)
+	for _, action := range append(append([]string{}, Permissions[resourceType]...), extra...) {
+		fmt.Fprintf(&suggestion, "- %s\n", action)
+	}
+	return suggestion.String()
+}
@@ -1,6 +1,12 @@
 package counter
 
-import "aws-resource-discovery/pkg/interfaces"
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
 
 type RdsCounter struct {
 	BaseCounter
@@ -13,8 +19,43 @@ func NewRdsCounter(client interfaces.CloudControlClient) *RdsCounter {
 			Result:   interfaces.CounterResult{CounterClass: "AWS::RDS::DBInstance"},
 			TypeName: "AWS::RDS::DBInstance",
 			PermissionSuggestionFunc: func() string {
-				return "\nTo scan RDS instances, the provided credentials must have the following permissions:\n- rds:DescribeDBInstances\n"
+				return permissionSuggestion("RDS instances", "AWS::RDS::DBInstance")
 			},
 		},
 	}
 }
+
+// NewNativeRdsCounter creates a new RdsCounter that lists instances with the RDS API.
+func NewNativeRdsCounter(client interfaces.RDSClient) *RdsCounter {
+	counter := NewRdsCounter(nil)
+	counter.NativeListFunc = func() ([]interfaces.ResourceRecord, error) {
+		return rdsInstances(client)
+	}
+	return counter
+}
+
+// rdsInstances lists the RDS DB instances.
+func rdsInstances(client interfaces.RDSClient) ([]interfaces.ResourceRecord, error) {
+	input := &rds.DescribeDBInstancesInput{MaxRecords: aws.Int32(100)}
+	resources := []interfaces.ResourceRecord{}
+	paginator := rds.NewDescribeDBInstancesPaginator(client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range output.DBInstances {
+			tags := make(map[string]string, len(instance.TagList))
+			for _, tag := range instance.TagList {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			resources = append(resources, interfaces.ResourceRecord{
+				Identifier: aws.ToString(instance.DBInstanceIdentifier),
+				Arn:        aws.ToString(instance.DBInstanceArn),
+				State:      aws.ToString(instance.DBInstanceStatus),
+				Tags:       tags,
+			})
+		}
+	}
+	return resources, nil
+}
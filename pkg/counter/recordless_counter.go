@@ -0,0 +1,38 @@
+package counter
+
+import "aws-resource-discovery/pkg/interfaces"
+
+// RecordlessCounter wraps a counter and drops the resource records of its
+// result once it has counted, along with the wrapped counter, so that the
+// records are not kept when nothing reads them.
+type RecordlessCounter struct {
+	Counter interfaces.Counter
+	Result  interfaces.CounterResult
+}
+
+// WithoutRecords wraps every counter in a RecordlessCounter. The records are
+// still listed, and used by the wrapped tag filters and reconciliation.
+func WithoutRecords(counters []interfaces.Counter) []interfaces.Counter {
+	wrapped := make([]interfaces.Counter, 0, len(counters))
+	for _, cnt := range counters {
+		wrapped = append(wrapped, &RecordlessCounter{Counter: cnt})
+	}
+	return wrapped
+}
+
+// Call performs the counting of the wrapped counter and keeps its result
+// without the records.
+func (c *RecordlessCounter) Call() {
+	c.Counter.Call()
+	c.Result = c.Counter.GetResult()
+	c.Result.Resources = nil
+	c.Counter = nil
+}
+
+// GetResult returns the counter result.
+func (c *RecordlessCounter) GetResult() interfaces.CounterResult {
+	if c.Counter != nil {
+		return c.Counter.GetResult()
+	}
+	return c.Result
+}
@@ -0,0 +1,30 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordlessCounter_Call(t *testing.T) {
+	mockCounter := new(mocks.MockCounter)
+	mockCounter.On("Call").Return()
+	mockCounter.On("GetResult").Return(interfaces.CounterResult{
+		Count:        2,
+		CounterClass: "AWS::EC2::Instance",
+		Resources:    []interfaces.ResourceRecord{{Identifier: "i-1"}, {Identifier: "i-2"}},
+	})
+
+	counters := WithoutRecords([]interfaces.Counter{mockCounter})
+	assert.Equal(t, "AWS::EC2::Instance", counters[0].GetResult().CounterClass)
+
+	counters[0].Call()
+
+	result := counters[0].GetResult()
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, "AWS::EC2::Instance", result.CounterClass)
+	assert.Nil(t, result.Resources)
+	mockCounter.AssertNumberOfCalls(t, "Call", 1)
+}
@@ -0,0 +1,27 @@
+package counter
+
+import "aws-resource-discovery/pkg/interfaces"
+
+// StaticCounter is a counter whose count was already produced by another source.
+type StaticCounter struct {
+	Result interfaces.CounterResult
+}
+
+// NewStaticCounter creates a new StaticCounter.
+func NewStaticCounter(counterClass string, count int, source string) *StaticCounter {
+	return &StaticCounter{
+		Result: interfaces.CounterResult{
+			Count:        count,
+			CounterClass: counterClass,
+			Source:       source,
+		},
+	}
+}
+
+// Call does nothing as the count is already known.
+func (c *StaticCounter) Call() {}
+
+// GetResult returns the counter result.
+func (c *StaticCounter) GetResult() interfaces.CounterResult {
+	return c.Result
+}
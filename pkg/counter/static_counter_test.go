@@ -0,0 +1,19 @@
+package counter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticCounter_Call(t *testing.T) {
+	counter := NewStaticCounter("AWS::EC2::Instance", 7, "resource-explorer")
+
+	counter.Call()
+
+	result := counter.GetResult()
+	assert.Equal(t, 7, result.Count)
+	assert.Equal(t, "AWS::EC2::Instance", result.CounterClass)
+	assert.Equal(t, "resource-explorer", result.Source)
+	assert.Nil(t, result.Error)
+}
@@ -0,0 +1,220 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+)
+
+// taggingResourceTypes maps counter classes to the Resource Groups Tagging API
+// resource type that holds the tags of their resources.
+var taggingResourceTypes = map[string]string{
+	"AWS::DynamoDB::Table":  "dynamodb:table",
+	"AWS::EC2::Instance":    "ec2:instance",
+	"AWS::EC2::Volume":      "ec2:volume",
+	"AWS::ECR::Repository":  "ecr:repository",
+	"AWS::ECS::Cluster":     "ecs:service",
+	"AWS::EFS::FileSystem":  "elasticfilesystem:file-system",
+	"AWS::Lambda::Function": "lambda:function",
+	"AWS::RDS::DBInstance":  "rds:db",
+	"AWS::S3::Bucket":       "s3",
+}
+
+// Labels of the filters reported in CounterResult.Filtered.
+const (
+	// filteredNotIncluded counts the resources that matched none of the include filters.
+	filteredNotIncluded = "INCLUDE_TAGS"
+	// filteredExcludedPrefix prefixes the exclude filter that removed a resource.
+	filteredExcludedPrefix = "EXCLUDE_TAGS "
+)
+
+// TagFilterCounter wraps a counter and removes the resources that do not match
+// the include tag filters or match an exclude tag filter from its count. The
+// remaining resources keep the tags it looked up, so it also serves to tag the
+// resources of a counter without filtering them.
+type TagFilterCounter struct {
+	Counter       interfaces.Counter
+	TaggingClient interfaces.ResourceGroupsTaggingClient
+	Include       []config.TagFilter
+	Exclude       []config.TagFilter
+	Result        interfaces.CounterResult
+}
+
+// NewTagFilterCounter creates a new TagFilterCounter.
+func NewTagFilterCounter(counter interfaces.Counter, client interfaces.ResourceGroupsTaggingClient, include, exclude []config.TagFilter) *TagFilterCounter {
+	return &TagFilterCounter{
+		Counter:       counter,
+		TaggingClient: client,
+		Include:       include,
+		Exclude:       exclude,
+	}
+}
+
+// WithTagFilters wraps every counter in a TagFilterCounter.
+func WithTagFilters(counters []interfaces.Counter, client interfaces.ResourceGroupsTaggingClient, include, exclude []config.TagFilter) []interfaces.Counter {
+	wrapped := make([]interfaces.Counter, 0, len(counters))
+	for _, cnt := range counters {
+		wrapped = append(wrapped, NewTagFilterCounter(cnt, client, include, exclude))
+	}
+	return wrapped
+}
+
+// Call performs the counting of the wrapped counter and filters its resources.
+// Resources whose tags cannot be read, because the Tagging API does not support
+// their type or the lookup failed, are kept and counted as unfiltered. A failed
+// lookup also sets the permission suggestion of the result.
+func (c *TagFilterCounter) Call() {
+	c.Counter.Call()
+	c.Result = c.Counter.GetResult()
+	if c.Result.Error != nil || len(c.Result.Resources) == 0 {
+		return
+	}
+
+	if err := c.addTags(c.Result.Resources); err != nil {
+		log.Printf("Error reading tags for %s: %v", c.Result.CounterClass, err)
+		c.Result.PermissionSuggestion = permissionSuggestion("the tags of "+c.Result.CounterClass, c.Result.CounterClass, TagsPermission)
+	}
+
+	filtering := len(c.Include) > 0 || len(c.Exclude) > 0
+	kept := []interfaces.ResourceRecord{}
+	filtered := map[string]int{}
+	for _, resource := range c.Result.Resources {
+		if resource.Tags == nil {
+			kept = append(kept, resource)
+			if filtering {
+				c.Result.Unfiltered += max(resource.Count, 1)
+			}
+			continue
+		}
+		label, removed := c.filter(resource.Tags)
+		if !removed {
+			kept = append(kept, resource)
+			continue
+		}
+		units := max(resource.Count, 1)
+		filtered[label] += units
+		c.Result.Count -= units
+	}
+	c.Result.Resources = kept
+	c.Result.Filtered = filtered
+}
+
+// filter returns the label of the filter that removes a resource with the given tags.
+func (c *TagFilterCounter) filter(tags map[string]string) (string, bool) {
+	for _, exclude := range c.Exclude {
+		if exclude.Matches(tags) {
+			return filteredExcludedPrefix + exclude.String(), true
+		}
+	}
+	if len(c.Include) == 0 {
+		return "", false
+	}
+	for _, include := range c.Include {
+		if include.Matches(tags) {
+			return "", false
+		}
+	}
+	return filteredNotIncluded, true
+}
+
+// addTags sets the tags of the resources the listing API returned without tags
+// from the Resource Groups Tagging API, and an empty map for the ones it has no
+// tags for. The tags of resources of the types the Tagging API does not
+// support, or whose lookup failed, are left nil.
+func (c *TagFilterCounter) addTags(resources []interfaces.ResourceRecord) error {
+	missing := false
+	for _, resource := range resources {
+		if resource.Tags == nil {
+			missing = true
+			break
+		}
+	}
+	resourceType, ok := taggingResourceTypes[c.Result.CounterClass]
+	if !missing || !ok {
+		return nil
+	}
+
+	byArn, byName, err := c.taggedResources(resourceType)
+	if err != nil {
+		return err
+	}
+	for i := range resources {
+		if resources[i].Tags != nil {
+			continue
+		}
+		if tags, ok := byArn[resources[i].Arn]; ok {
+			resources[i].Tags = tags
+		} else if tags, ok := byName[taggingName(c.Result.CounterClass, resources[i].Identifier)]; ok {
+			resources[i].Tags = tags
+		} else {
+			resources[i].Tags = map[string]string{}
+		}
+	}
+	return nil
+}
+
+// taggedResources returns the tags of every tagged resource of a Tagging API
+// resource type, keyed by ARN and by the resource name at the end of the ARN.
+func (c *TagFilterCounter) taggedResources(resourceType string) (map[string]map[string]string, map[string]map[string]string, error) {
+	byArn := map[string]map[string]string{}
+	byName := map[string]map[string]string{}
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: []string{resourceType},
+		ResourcesPerPage:    aws.Int32(100),
+	}
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(c.TaggingClient, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, mapping := range output.ResourceTagMappingList {
+			tags := make(map[string]string, len(mapping.Tags))
+			for _, tag := range mapping.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			resourceArn := aws.ToString(mapping.ResourceARN)
+			byArn[resourceArn] = tags
+			byName[arnResourceName(resourceArn)] = tags
+		}
+	}
+	return byArn, byName, nil
+}
+
+// arnResourceName returns the resource name of an ARN without its resource type,
+// e.g. "i-0123" for an instance or "team/app" for an ECR repository.
+func arnResourceName(resourceArn string) string {
+	parsed, err := arn.Parse(resourceArn)
+	if err != nil {
+		return resourceArn
+	}
+	if i := strings.IndexAny(parsed.Resource, "/:"); i >= 0 {
+		return parsed.Resource[i+1:]
+	}
+	return parsed.Resource
+}
+
+// taggingName returns the name under which the tags of a resource are found.
+// Container images carry the tags of their repository.
+func taggingName(counterClass, identifier string) string {
+	if counterClass == "AWS::ECR::Repository" {
+		if i := strings.IndexAny(identifier, ":@"); i >= 0 {
+			return identifier[:i]
+		}
+	}
+	return identifier
+}
+
+// GetResult returns the counter result.
+func (c *TagFilterCounter) GetResult() interfaces.CounterResult {
+	if c.Result.CounterClass == "" {
+		return c.Counter.GetResult()
+	}
+	return c.Result
+}
@@ -0,0 +1,143 @@
+package counter
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/mocks"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// resourcesCounter is a counter that returns a fixed list of resources.
+type resourcesCounter struct {
+	Result interfaces.CounterResult
+}
+
+func (c *resourcesCounter) Call() {}
+
+func (c *resourcesCounter) GetResult() interfaces.CounterResult {
+	return c.Result
+}
+
+func TestTagFilterCounter_Call(t *testing.T) {
+	mockClient := new(mocks.MockResourceGroupsTaggingClient)
+	inner := &resourcesCounter{Result: interfaces.CounterResult{
+		CounterClass: "AWS::Lambda::Function",
+		Count:        4,
+		Resources: []interfaces.ResourceRecord{
+			{Identifier: "api", Arn: "arn:aws:lambda:us-east-1:123456789012:function:api"},
+			{Identifier: "sandbox-api"},
+			{Identifier: "old-api"},
+			{Identifier: "untagged"},
+		},
+	}}
+	counter := NewTagFilterCounter(inner, mockClient,
+		[]config.TagFilter{{Key: "env"}},
+		[]config.TagFilter{{Key: "env", Value: "sandbox"}, {Key: "rc-exclude", Value: "true"}})
+
+	mockClient.On("GetResources", mock.Anything, mock.MatchedBy(func(input *resourcegroupstaggingapi.GetResourcesInput) bool {
+		return input.ResourceTypeFilters[0] == "lambda:function"
+	})).Return(&resourcegroupstaggingapi.GetResourcesOutput{
+		ResourceTagMappingList: []types.ResourceTagMapping{
+			{ResourceARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:api"), Tags: []types.Tag{{Key: aws.String("env"), Value: aws.String("prod")}}},
+			{ResourceARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:sandbox-api"), Tags: []types.Tag{{Key: aws.String("env"), Value: aws.String("sandbox")}}},
+			{ResourceARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:old-api"), Tags: []types.Tag{
+				{Key: aws.String("env"), Value: aws.String("prod")},
+				{Key: aws.String("rc-exclude"), Value: aws.String("true")},
+			}},
+		},
+	}, nil).Once()
+
+	counter.Call()
+
+	result := counter.GetResult()
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, "api", result.Resources[0].Identifier)
+	assert.Equal(t, map[string]int{
+		"EXCLUDE_TAGS env=sandbox":     1,
+		"EXCLUDE_TAGS rc-exclude=true": 1,
+		"INCLUDE_TAGS":                 1,
+	}, result.Filtered)
+	mockClient.AssertExpectations(t)
+}
+
+func TestTagFilterCounter_CallWithTaggedResources(t *testing.T) {
+	mockClient := new(mocks.MockResourceGroupsTaggingClient)
+	inner := &resourcesCounter{Result: interfaces.CounterResult{
+		CounterClass: "AWS::ECS::Cluster",
+		Count:        6,
+		Resources: []interfaces.ResourceRecord{
+			{Identifier: "ecs-svc/1", Tags: map[string]string{"env": "sandbox"}, Count: 4},
+			{Identifier: "ecs-svc/2", Tags: map[string]string{}, Count: 2},
+		},
+	}}
+	counter := NewTagFilterCounter(inner, mockClient, nil, []config.TagFilter{{Key: "env", Value: "sandbox"}})
+
+	// Resources that already carry tags are not looked up
+	counter.Call()
+
+	assert.Equal(t, 2, counter.GetResult().Count)
+	assert.Equal(t, map[string]int{"EXCLUDE_TAGS env=sandbox": 4}, counter.GetResult().Filtered)
+	mockClient.AssertNotCalled(t, "GetResources", mock.Anything, mock.Anything)
+}
+
+func TestTagFilterCounter_CallWithTaggingError(t *testing.T) {
+	mockClient := new(mocks.MockResourceGroupsTaggingClient)
+	inner := &resourcesCounter{Result: interfaces.CounterResult{
+		CounterClass: "AWS::DynamoDB::Table",
+		Count:        1,
+		Resources:    []interfaces.ResourceRecord{{Identifier: "orders"}},
+	}}
+	counter := NewTagFilterCounter(inner, mockClient, []config.TagFilter{{Key: "env"}}, nil)
+
+	mockClient.On("GetResources", mock.Anything, mock.Anything).Return(nil, errors.New("test error")).Once()
+
+	counter.Call()
+
+	// A failed lookup leaves the resources unfiltered and suggests the permission
+	assert.Equal(t, 1, counter.GetResult().Count)
+	assert.Empty(t, counter.GetResult().Filtered)
+	assert.Equal(t, 1, counter.GetResult().Unfiltered)
+	assert.Contains(t, counter.GetResult().PermissionSuggestion, TagsPermission)
+	mockClient.AssertExpectations(t)
+}
+
+func TestTagFilterCounter_CallWithUnsupportedType(t *testing.T) {
+	mockClient := new(mocks.MockResourceGroupsTaggingClient)
+	inner := &resourcesCounter{Result: interfaces.CounterResult{
+		CounterClass: "AWS::ECR::PublicRepository",
+		Count:        7,
+		Resources:    []interfaces.ResourceRecord{{Identifier: "web", Count: 7}},
+	}}
+	counter := NewTagFilterCounter(inner, mockClient, []config.TagFilter{{Key: "env"}}, nil)
+
+	// The Tagging API does not support the type, so its images are kept
+	counter.Call()
+
+	assert.Equal(t, 7, counter.GetResult().Count)
+	assert.Empty(t, counter.GetResult().Filtered)
+	assert.Equal(t, 7, counter.GetResult().Unfiltered)
+	assert.Empty(t, counter.GetResult().PermissionSuggestion)
+	mockClient.AssertNotCalled(t, "GetResources", mock.Anything, mock.Anything)
+}
+
+func TestTagFilterCounter_GetResult(t *testing.T) {
+	counter := NewTagFilterCounter(NewEc2Counter(nil), nil, nil, nil)
+
+	assert.Equal(t, "AWS::EC2::Instance", counter.GetResult().CounterClass)
+}
+
+func TestArnResourceName(t *testing.T) {
+	assert.Equal(t, "i-0123", arnResourceName("arn:aws:ec2:us-east-1:123456789012:instance/i-0123"))
+	assert.Equal(t, "team/app", arnResourceName("arn:aws:ecr:us-east-1:123456789012:repository/team/app"))
+	assert.Equal(t, "orders", arnResourceName("arn:aws:dynamodb:us-east-1:123456789012:table/orders"))
+	assert.Equal(t, "bucket", arnResourceName("arn:aws:s3:::bucket"))
+	assert.Equal(t, "team/app", taggingName("AWS::ECR::Repository", "team/app:latest"))
+	assert.Equal(t, "team/app", taggingName("AWS::ECR::Repository", "team/app@sha256:0123"))
+}
@@ -6,16 +6,24 @@ import (
 	"aws-resource-discovery/pkg/config"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type STSClient interface {
 	AssumeRole(ctx context.Context, input *sts.AssumeRoleInput, opts ...func(*sts.Options)) (*sts.AssumeRoleOutput, error)
+	GetCallerIdentity(ctx context.Context, input *sts.GetCallerIdentityInput, opts ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+type IAMClient interface {
+	SimulatePrincipalPolicy(ctx context.Context, input *iam.SimulatePrincipalPolicyInput, opts ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+	GetRole(ctx context.Context, input *iam.GetRoleInput, opts ...func(*iam.Options)) (*iam.GetRoleOutput, error)
 }
 
 type CredentialsManager interface {
 	CredentialsFor(ctx context.Context, accountId, region string) (aws.Credentials, error)
+	RoleFor(accountId string) string
 }
 
 type SessionManager interface {
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+)
+
+type ConfigServiceClient interface {
+	SelectAggregateResourceConfig(ctx context.Context, input *configservice.SelectAggregateResourceConfigInput, opts ...func(*configservice.Options)) (*configservice.SelectAggregateResourceConfigOutput, error)
+	DescribeConfigurationAggregatorSourcesStatus(ctx context.Context, input *configservice.DescribeConfigurationAggregatorSourcesStatusInput, opts ...func(*configservice.Options)) (*configservice.DescribeConfigurationAggregatorSourcesStatusOutput, error)
+}
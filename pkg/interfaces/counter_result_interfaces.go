@@ -5,6 +5,54 @@ type CounterResult struct {
 	CounterClass         string
 	Error                error
 	PermissionSuggestion string
+	// Source names the data source that produced the count.
+	Source string
+	// Peak is the highest count observed over the lookback window. It is
+	// zero when the counter did not collect historical metrics.
+	Peak int
+	// Average is the mean count over the lookback window.
+	Average float64
+	// HasHistory is set when Peak and Average were read from a metric.
+	HasHistory bool
+	// Details holds per-resource capacity records, such as the min, max and
+	// desired capacity of Auto Scaling groups, that are logged to the capacity
+	// report after the account and region columns.
+	Details [][]string
+	// Resources lists the counted resources when the counter lists them
+	// individually.
+	Resources []ResourceRecord
+	// Reconciliation compares the resources with the CloudControl listing of
+	// the same type. It is nil unless the scan runs in reconcile mode.
+	Reconciliation *Reconciliation
+	// Filtered holds the number of units each tag filter removed from the count.
+	Filtered map[string]int
+	// Unfiltered is the number of units the tag filters were not applied to,
+	// because the tags of their resources could not be read.
+	Unfiltered int
+}
+
+// ResourceRecord describes a single counted resource. Arn, State and Tags are
+// only set when the listing API returns them.
+type ResourceRecord struct {
+	Identifier string
+	Arn        string
+	State      string
+	Tags       map[string]string
+	// Count is the number of units the resource adds to the count of its type
+	// when that is not one, such as the running containers of an ECS service.
+	Count int
+	// Tasks is the number of running tasks behind Count for ECS services,
+	// used to convert their task history into containers.
+	Tasks int
+}
+
+// Reconciliation holds the CloudControl count of a resource type and the
+// identifiers returned by only one of the native API and CloudControl.
+type Reconciliation struct {
+	CloudControlCount int
+	OnlyNative        []string
+	OnlyCloudControl  []string
+	Error             error
 }
 
 func (c *CounterResult) Success() bool {
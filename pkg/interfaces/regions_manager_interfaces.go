@@ -8,4 +8,7 @@ import (
 
 type RegionsManager interface {
 	GetRegions(ctx context.Context, cfg aws.Config, specifiedRegion string, logger Logger) ([]string, error)
+	// AccountRegions returns the status of every region of the partition in
+	// the account of the configuration credentials.
+	AccountRegions(ctx context.Context, cfg aws.Config) (map[string]string, error)
 }
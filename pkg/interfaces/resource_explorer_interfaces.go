@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2"
+)
+
+type ResourceExplorerClient interface {
+	Search(ctx context.Context, input *resourceexplorer2.SearchInput, opts ...func(*resourceexplorer2.Options)) (*resourceexplorer2.SearchOutput, error)
+	ListIndexesForMembers(ctx context.Context, input *resourceexplorer2.ListIndexesForMembersInput, opts ...func(*resourceexplorer2.Options)) (*resourceexplorer2.ListIndexesForMembersOutput, error)
+}
+
+// CountSource provides resource counts gathered ahead of the per-account scan.
+type CountSource interface {
+	Name() string
+	// Count returns the count of a resource type in an account and region, and
+	// whether the source covers it. An empty region asks for all regions.
+	Count(accountId, region, resourceType string) (int, bool)
+}
@@ -14,16 +14,73 @@ type ResourceScanner interface {
 }
 
 type ResourceTotals struct {
-	Buckets                 int
-	ContainerHosts          int
-	ContainerRegistryImages int
-	Databases               int
-	NonOsDisks              int
-	ServerlessContainers    int
-	ServerlessFunctions     int
-	VirtualMachines         int
+	Buckets                 int `json:"storage_buckets"`
+	ContainerHosts          int `json:"container_hosts"`
+	ContainerRegistryImages int `json:"container_registry_images"`
+	Databases               int `json:"databases"`
+	NonOsDisks              int `json:"non_os_disks"`
+	ServerlessContainers    int `json:"serverless_containers"`
+	ServerlessFunctions     int `json:"serverless_functions"`
+	VirtualMachines         int `json:"virtual_machines"`
+	// Peak holds the highest totals observed over the lookback window. It is
+	// nil unless historical metrics were collected.
+	Peak *ResourceTotals `json:"peak,omitempty"`
+	// ByTag holds the totals per value of the grouping tag, with resources
+	// without the tag under UntaggedGroup. It is nil unless grouping by tag.
+	ByTag map[string]*ResourceTotals `json:"by_tag,omitempty"`
 }
 
+// BillingCategory is the total of a billing category, such as virtual machines.
+type BillingCategory struct {
+	Name  string
+	Count int
+}
+
+// Categories returns the totals of every billing category in the order of the
+// console tables.
+func (t ResourceTotals) Categories() []BillingCategory {
+	return []BillingCategory{
+		{"Storage Buckets", t.Buckets},
+		{"Container Hosts", t.ContainerHosts},
+		{"Databases", t.Databases},
+		{"Non-OS Disks", t.NonOsDisks},
+		{"Serverless Containers", t.ServerlessContainers},
+		{"Serverless Functions", t.ServerlessFunctions},
+		{"Virtual Machines", t.VirtualMachines},
+		{"Container Registry Images", t.ContainerRegistryImages},
+	}
+}
+
+// Add adds other to the totals, including its peak and per-tag totals.
+func (t *ResourceTotals) Add(other ResourceTotals) {
+	t.Buckets += other.Buckets
+	t.ContainerHosts += other.ContainerHosts
+	t.ContainerRegistryImages += other.ContainerRegistryImages
+	t.Databases += other.Databases
+	t.NonOsDisks += other.NonOsDisks
+	t.ServerlessContainers += other.ServerlessContainers
+	t.ServerlessFunctions += other.ServerlessFunctions
+	t.VirtualMachines += other.VirtualMachines
+	if other.Peak != nil {
+		if t.Peak == nil {
+			t.Peak = &ResourceTotals{}
+		}
+		t.Peak.Add(*other.Peak)
+	}
+	for group, totals := range other.ByTag {
+		if t.ByTag == nil {
+			t.ByTag = map[string]*ResourceTotals{}
+		}
+		if t.ByTag[group] == nil {
+			t.ByTag[group] = &ResourceTotals{}
+		}
+		t.ByTag[group].Add(*totals)
+	}
+}
+
+// UntaggedGroup is the ByTag key of the resources without the grouping tag.
+const UntaggedGroup = "untagged"
+
 type Scanner interface {
 	ScanSingleAccount(ctx context.Context, config config.Config, logger Logger) ScanResult
 	ScanOrganization(ctx context.Context, config config.Config, logger Logger) ScanResult
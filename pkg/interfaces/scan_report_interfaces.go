@@ -0,0 +1,152 @@
+package interfaces
+
+// Discrepancy describes a resource type whose native and CloudControl listings
+// disagree in an account and region.
+type Discrepancy struct {
+	AccountId         string
+	Region            string
+	ResourceType      string
+	NativeCount       int
+	CloudControlCount int
+	// Mismatched is the number of identifiers returned by only one of the APIs.
+	Mismatched int
+	// OnlyNative and OnlyCloudControl hold sample identifiers returned by only
+	// one of the APIs.
+	OnlyNative       []string
+	OnlyCloudControl []string
+}
+
+// Difference returns the mismatched identifiers as a percentage of the larger count.
+func (d *Discrepancy) Difference() float64 {
+	larger := max(d.NativeCount, d.CloudControlCount)
+	if larger == 0 {
+		return 0
+	}
+	return float64(d.Mismatched) / float64(larger) * 100
+}
+
+// Reasons for which an account of the organization is not scanned.
+const (
+	SkipReasonExcluded     = "excluded"
+	SkipReasonSuspended    = "suspended"
+	SkipReasonLookupFailed = "lookup-failed"
+	SkipReasonDuplicate    = "duplicate"
+)
+
+// SkippedAccount describes an account of the organization that was not scanned.
+type SkippedAccount struct {
+	AccountId string
+	Reason    string
+	// Detail names the filter, status or error that caused the account to be skipped.
+	Detail string
+}
+
+// Statuses of a region in an account.
+const (
+	RegionEnabled  = "enabled"
+	RegionDisabled = "disabled"
+	// RegionBlocked marks a region a service control policy denies in the account.
+	RegionBlocked = "blocked"
+)
+
+// Types of policy that deny an action in a policy simulation.
+const (
+	DeniedBySCP                = "scp"
+	DeniedByPermissionBoundary = "permissions-boundary"
+	DeniedByIdentityPolicy     = "identity-policy"
+)
+
+// DeniedAction describes an IAM action the scanning role cannot perform in an account.
+type DeniedAction struct {
+	AccountId string
+	Action    string
+	// DeniedBy is the type of policy that decided the denial.
+	DeniedBy string
+	// Decision is the decision of the simulation, e.g. explicitDeny or implicitDeny.
+	Decision string
+}
+
+// PermissionSuggestion describes the permissions a counter needs, with the
+// accounts in which it failed.
+type PermissionSuggestion struct {
+	Message  string
+	Accounts []string
+}
+
+// ScanReport collects the findings of a scan that are reported after its totals.
+type ScanReport struct {
+	Discrepancies []Discrepancy
+	// Filtered holds the number of units each tag filter removed from the totals.
+	Filtered map[string]int
+	// Unfiltered holds, by resource type, the number of units the tag filters
+	// were not applied to because their tags could not be read.
+	Unfiltered map[string]int
+	// SkippedAccounts lists the accounts left out of an organization scan.
+	SkippedAccounts []SkippedAccount
+	// AccountRoles holds the name of the role assumed in each scanned account.
+	AccountRoles map[string]string
+	// PermissionSuggestions holds, by resource type, the permissions needed by
+	// the counters that failed.
+	PermissionSuggestions map[string]*PermissionSuggestion
+	// DeniedActions lists the actions the policy simulation found denied.
+	DeniedActions []DeniedAction
+	// RegionStatuses holds, by account, the status of the regions considered
+	// for the scan. Disabled regions are not scanned, and the scan of blocked
+	// regions stops at their first call.
+	RegionStatuses map[string]map[string]string
+}
+
+// SkipAccount records an account that is not scanned.
+func (r *ScanReport) SkipAccount(accountId, reason, detail string) {
+	r.SkippedAccounts = append(r.SkippedAccounts, SkippedAccount{AccountId: accountId, Reason: reason, Detail: detail})
+}
+
+// SetRegionStatuses records the status of the regions of an account.
+func (r *ScanReport) SetRegionStatuses(accountId string, statuses map[string]string) {
+	if r.RegionStatuses == nil {
+		r.RegionStatuses = map[string]map[string]string{}
+	}
+	r.RegionStatuses[accountId] = statuses
+}
+
+// SetRegionStatus records the status of a region of an account.
+func (r *ScanReport) SetRegionStatus(accountId, region, status string) {
+	if r.RegionStatuses == nil {
+		r.RegionStatuses = map[string]map[string]string{}
+	}
+	if r.RegionStatuses[accountId] == nil {
+		r.RegionStatuses[accountId] = map[string]string{}
+	}
+	r.RegionStatuses[accountId][region] = status
+}
+
+// SuggestPermissions records the permissions a counter of the resource type
+// needs in an account where it failed.
+func (r *ScanReport) SuggestPermissions(resourceType, accountId, message string) {
+	if r.PermissionSuggestions == nil {
+		r.PermissionSuggestions = map[string]*PermissionSuggestion{}
+	}
+	suggestion, ok := r.PermissionSuggestions[resourceType]
+	if !ok {
+		suggestion = &PermissionSuggestion{Message: message}
+		r.PermissionSuggestions[resourceType] = suggestion
+	}
+	for _, account := range suggestion.Accounts {
+		if account == accountId {
+			return
+		}
+	}
+	suggestion.Accounts = append(suggestion.Accounts, accountId)
+}
+
+// DiscrepanciesAbove returns the discrepancies whose difference exceeds the
+// tolerance, in percent.
+func (r *ScanReport) DiscrepanciesAbove(tolerance float64) []Discrepancy {
+	exceeded := []Discrepancy{}
+	for _, discrepancy := range r.Discrepancies {
+		if discrepancy.Difference() > tolerance {
+			exceeded = append(exceeded, discrepancy)
+		}
+	}
+	return exceeded
+}
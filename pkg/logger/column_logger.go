@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+)
+
+// columnLogger inserts a fixed value into every record it logs.
+type columnLogger struct {
+	interfaces.Logger
+	index int
+	value string
+}
+
+// WithColumn returns a logger that inserts value at index into every record
+// before passing it to logger. Messages logged with Logf are left unchanged.
+func WithColumn(logger interfaces.Logger, index int, value string) interfaces.Logger {
+	return &columnLogger{Logger: logger, index: index, value: value}
+}
+
+func (l *columnLogger) Log(record []string) error {
+	if len(record) < l.index {
+		return l.Logger.Log(record)
+	}
+	columns := make([]string, 0, len(record)+1)
+	columns = append(columns, record[:l.index]...)
+	columns = append(columns, l.value)
+	columns = append(columns, record[l.index:]...)
+	return l.Logger.Log(columns)
+}
@@ -0,0 +1,23 @@
+package logger_test
+
+import (
+	"testing"
+
+	"aws-resource-discovery/pkg/logger"
+	"aws-resource-discovery/pkg/mocks"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestColumnLogger_Log(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Log", []string{"123456789012", "Root/Engineering", "us-east-1", "AWS::EC2::Instance", "2"}).Return(nil)
+	mockLogger.On("Logf", "Failed to scan %s", "us-east-1").Return(nil)
+
+	columnLogger := logger.WithColumn(mockLogger, 1, "Root/Engineering")
+	columnLogger.Log([]string{"123456789012", "us-east-1", "AWS::EC2::Instance", "2"})
+	columnLogger.Logf("Failed to scan %s", "us-east-1")
+
+	mockLogger.AssertExpectations(t)
+	mockLogger.AssertNotCalled(t, "Log", mock.MatchedBy(func(record []string) bool { return len(record) == 4 }))
+}
@@ -7,19 +7,33 @@ import (
 	"os"
 )
 
+// messageKind is the first column of the messages of a logger with a header.
+const messageKind = "message"
+
 type csvLogger struct {
 	file   *os.File
 	writer *csv.Writer
+	width  int
 }
 
-func NewCSVLogger(filename string) (interfaces.Logger, error) {
+// NewCSVLogger creates the CSV file and writes the header, when one is given,
+// as its first record. Messages logged with Logf are then written as records
+// of the width of the header, with "message" in the first column and the
+// message in the last one.
+func NewCSVLogger(filename string, header ...string) (interfaces.Logger, error) {
 	file, err := os.Create(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	writer := csv.NewWriter(file)
-	return &csvLogger{file: file, writer: writer}, nil
+	logger := &csvLogger{file: file, writer: csv.NewWriter(file), width: len(header)}
+	if len(header) > 0 {
+		if err := logger.Log(header); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return logger, nil
 }
 
 func (l *csvLogger) Log(record []string) error {
@@ -32,8 +46,14 @@ func (l *csvLogger) Log(record []string) error {
 }
 
 func (l *csvLogger) Logf(format string, args ...interface{}) error {
-	record := fmt.Sprintf(format, args...)
-	return l.Log([]string{record})
+	message := fmt.Sprintf(format, args...)
+	if l.width < 2 {
+		return l.Log([]string{message})
+	}
+	record := make([]string, l.width)
+	record[0] = messageKind
+	record[l.width-1] = message
+	return l.Log(record)
 }
 
 func (l *csvLogger) Close() error {
@@ -64,3 +64,22 @@ func TestCSVLogger_Logf(t *testing.T) {
 	expectedContent := "Formatted data: 42\n"
 	assert.Equal(t, expectedContent, string(content))
 }
+
+func TestCSVLogger_Header(t *testing.T) {
+	filename := "test_csv_logger.csv"
+	defer os.Remove(filename)
+
+	logger, err := logger.NewCSVLogger(filename, "account", "region")
+	assert.NoError(t, err)
+	defer logger.Close()
+
+	err = logger.Log([]string{"123456789012", "us-east-1"})
+	assert.NoError(t, err)
+
+	err = logger.Logf("Skipping region %s", "us-west-2")
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "account,region\n123456789012,us-east-1\nmessage,Skipping region us-west-2\n", string(content))
+}
@@ -3,57 +3,171 @@ package managers
 import (
 	"aws-resource-discovery/pkg/interfaces"
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
+// defaultRoleName is the scanning role deployed by the CloudFormation template.
+const defaultRoleName = "red-canary-resource-discovery-role"
+
 type credentialsManager struct {
 	awsRoleName string
 	stsClient   interfaces.STSClient
+	// fallbackRoleNames are tried in order when the role cannot be assumed.
+	fallbackRoleNames []string
+	// roleMap holds the role names to try for specific accounts instead of the defaults.
+	roleMap        map[string][]string
+	externalId     string
+	duration       time.Duration
+	sourceIdentity string
+	// partition is the AWS partition of the role ARNs.
+	partition string
+	// roles caches the role that was assumed in each account.
+	roles map[string]string
 }
 
-func NewCredentialsManager(roleName string, stsClient interfaces.STSClient) interfaces.CredentialsManager {
-	return &credentialsManager{
-		awsRoleName: roleName,
-		stsClient:   stsClient,
+// CredentialsOption configures how the credentials manager assumes roles.
+type CredentialsOption func(*credentialsManager)
+
+// WithFallbackRoleNames sets the role names tried, in order, in accounts where
+// the role cannot be assumed.
+func WithFallbackRoleNames(roleNames ...string) CredentialsOption {
+	return func(cm *credentialsManager) {
+		cm.fallbackRoleNames = roleNames
+	}
+}
+
+// WithRoleMap sets the role names tried, in order, in specific accounts.
+func WithRoleMap(roleMap map[string][]string) CredentialsOption {
+	return func(cm *credentialsManager) {
+		cm.roleMap = roleMap
+	}
+}
+
+// WithExternalId sets the external ID passed when assuming roles.
+func WithExternalId(externalId string) CredentialsOption {
+	return func(cm *credentialsManager) {
+		cm.externalId = externalId
 	}
 }
 
-func (cm *credentialsManager) createAssumeRoleInput(accountId, region string) *sts.AssumeRoleInput {
-	roleArn := cm.awsRoleArn(accountId)
-	if roleArn == "" {
-		roleArn = fmt.Sprintf("arn:aws:iam::%s:role/red-canary-resource-discovery-role", accountId)
+// WithDuration sets the duration of the role sessions.
+func WithDuration(duration time.Duration) CredentialsOption {
+	return func(cm *credentialsManager) {
+		cm.duration = duration
 	}
+}
+
+// WithSourceIdentity sets the source identity of the role sessions.
+func WithSourceIdentity(sourceIdentity string) CredentialsOption {
+	return func(cm *credentialsManager) {
+		cm.sourceIdentity = sourceIdentity
+	}
+}
 
+// WithPartition sets the AWS partition of the role ARNs, such as "aws-us-gov".
+func WithPartition(partition string) CredentialsOption {
+	return func(cm *credentialsManager) {
+		cm.partition = partition
+	}
+}
+
+func NewCredentialsManager(roleName string, stsClient interfaces.STSClient, options ...CredentialsOption) interfaces.CredentialsManager {
+	cm := &credentialsManager{
+		awsRoleName: roleName,
+		stsClient:   stsClient,
+		partition:   "aws",
+		roles:       map[string]string{},
+	}
+	for _, option := range options {
+		option(cm)
+	}
+	return cm
+}
+
+func (cm *credentialsManager) createAssumeRoleInput(accountId, region, roleName string) *sts.AssumeRoleInput {
 	input := &sts.AssumeRoleInput{
-		RoleArn:         aws.String(roleArn),
+		RoleArn:         aws.String(cm.awsRoleArn(accountId, roleName)),
 		RoleSessionName: aws.String(fmt.Sprintf("rc-aws-scan-%s-%s", accountId, region)),
 	}
+	if cm.externalId != "" {
+		input.ExternalId = aws.String(cm.externalId)
+	}
+	if cm.duration > 0 {
+		input.DurationSeconds = aws.Int32(int32(cm.duration.Seconds()))
+	}
+	if cm.sourceIdentity != "" {
+		input.SourceIdentity = aws.String(cm.sourceIdentity)
+	}
 
 	return input
 }
 
+// CredentialsFor assumes the first role that can be assumed in the account,
+// starting with the role that was assumed in it before.
 func (cm *credentialsManager) CredentialsFor(ctx context.Context, accountId, region string) (aws.Credentials, error) {
-	input := cm.createAssumeRoleInput(accountId, region)
-
-	assumeRoleOutput, err := cm.stsClient.AssumeRole(ctx, input)
-	if err != nil {
-		return aws.Credentials{}, fmt.Errorf("failed to assume role: %w", err)
+	errs := []error{}
+	roleNames := cm.roleNames(accountId)
+	for _, roleName := range roleNames {
+		assumeRoleOutput, err := cm.stsClient.AssumeRole(ctx, cm.createAssumeRoleInput(accountId, region, roleName))
+		if err != nil {
+			if len(roleNames) > 1 {
+				err = fmt.Errorf("%s: %w", roleName, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		cm.roles[accountId] = roleName
+		return aws.Credentials{
+			AccessKeyID:     aws.ToString(assumeRoleOutput.Credentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(assumeRoleOutput.Credentials.SecretAccessKey),
+			SessionToken:    aws.ToString(assumeRoleOutput.Credentials.SessionToken),
+			CanExpire:       true,
+			Expires:         *assumeRoleOutput.Credentials.Expiration,
+		}, nil
 	}
-	return aws.Credentials{
-		AccessKeyID:     aws.ToString(assumeRoleOutput.Credentials.AccessKeyId),
-		SecretAccessKey: aws.ToString(assumeRoleOutput.Credentials.SecretAccessKey),
-		SessionToken:    aws.ToString(assumeRoleOutput.Credentials.SessionToken),
-		CanExpire:       true,
-		Expires:         *assumeRoleOutput.Credentials.Expiration,
-	}, nil
+	return aws.Credentials{}, fmt.Errorf("failed to assume role: %w", errors.Join(errs...))
 }
 
-func (cm *credentialsManager) awsRoleArn(accountId string) string {
-	if cm.awsRoleName == "" {
-		return ""
+// RoleFor returns the name of the role that was assumed in the account, or an
+// empty string when no role was assumed in it.
+func (cm *credentialsManager) RoleFor(accountId string) string {
+	return cm.roles[accountId]
+}
+
+// roleNames returns the role names to try in an account, in order.
+func (cm *credentialsManager) roleNames(accountId string) []string {
+	candidates, ok := cm.roleMap[accountId]
+	if !ok {
+		candidates = append([]string{cm.awsRoleName}, cm.fallbackRoleNames...)
+	}
+
+	roleNames := []string{}
+	if role, ok := cm.roles[accountId]; ok {
+		roleNames = append(roleNames, role)
 	}
-	return fmt.Sprintf("arn:aws:iam::%s:role/%s", accountId, cm.awsRoleName)
+	for _, roleName := range candidates {
+		if roleName == "" {
+			continue
+		}
+		duplicate := false
+		for _, name := range roleNames {
+			duplicate = duplicate || name == roleName
+		}
+		if !duplicate {
+			roleNames = append(roleNames, roleName)
+		}
+	}
+	if len(roleNames) == 0 {
+		roleNames = append(roleNames, defaultRoleName)
+	}
+	return roleNames
+}
+
+func (cm *credentialsManager) awsRoleArn(accountId, roleName string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", cm.partition, accountId, roleName)
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/aws-sdk-go-v2/service/sts/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestCredentialsManager_CredentialsFor(t *testing.T) {
@@ -84,10 +85,25 @@ func TestCredentialsManager_createAssumeRoleInput(t *testing.T) {
 	roleArn := "arn:aws:iam::123456789012:role/test-role"
 	sessionName := "rc-aws-scan-123456789012-us-east-1"
 
-	input := manager.createAssumeRoleInput(accountId, region)
+	input := manager.createAssumeRoleInput(accountId, region, "test-role")
 
 	assert.Equal(t, roleArn, aws.ToString(input.RoleArn))
 	assert.Equal(t, sessionName, aws.ToString(input.RoleSessionName))
+	assert.Nil(t, input.ExternalId)
+	assert.Nil(t, input.DurationSeconds)
+	assert.Nil(t, input.SourceIdentity)
+
+	manager = NewCredentialsManager("test-role", nil,
+		WithExternalId("external-id"),
+		WithDuration(2*time.Hour),
+		WithSourceIdentity("analyst@example.com"),
+	).(*credentialsManager)
+
+	input = manager.createAssumeRoleInput(accountId, region, "test-role")
+
+	assert.Equal(t, "external-id", aws.ToString(input.ExternalId))
+	assert.Equal(t, int32(7200), aws.ToInt32(input.DurationSeconds))
+	assert.Equal(t, "analyst@example.com", aws.ToString(input.SourceIdentity))
 }
 
 func TestCredentialsManager_awsRoleArn(t *testing.T) {
@@ -95,11 +111,58 @@ func TestCredentialsManager_awsRoleArn(t *testing.T) {
 
 	accountId := "123456789012"
 	expectedRoleArn := "arn:aws:iam::123456789012:role/test-role"
-	roleArn := manager.awsRoleArn(accountId)
+	roleArn := manager.awsRoleArn(accountId, "test-role")
 
 	assert.Equal(t, expectedRoleArn, roleArn)
+}
+
+func TestCredentialsManager_roleNames(t *testing.T) {
+	manager := NewCredentialsManager("", nil).(*credentialsManager)
+	assert.Equal(t, []string{"red-canary-resource-discovery-role"}, manager.roleNames("123456789012"))
+
+	manager = NewCredentialsManager("test-role", nil,
+		WithFallbackRoleNames("SecurityAuditRole", "test-role"),
+		WithRoleMap(map[string][]string{"210987654321": {"OrganizationAccountAccessRole"}}),
+	).(*credentialsManager)
+	assert.Equal(t, []string{"test-role", "SecurityAuditRole"}, manager.roleNames("123456789012"))
+	assert.Equal(t, []string{"OrganizationAccountAccessRole"}, manager.roleNames("210987654321"))
+
+	manager.roles["123456789012"] = "SecurityAuditRole"
+	assert.Equal(t, []string{"SecurityAuditRole", "test-role"}, manager.roleNames("123456789012"))
+}
+
+func TestCredentialsManager_CredentialsForWithFallbackRoles(t *testing.T) {
+	mockSTSClient := new(mocks.MockSTSClient)
+	manager := NewCredentialsManager("test-role", mockSTSClient, WithFallbackRoleNames("SecurityAuditRole")).(*credentialsManager)
+	expiration := time.Now().Add(1 * time.Hour)
+
+	mockSTSClient.On("AssumeRole", mock.Anything, mock.MatchedBy(func(input *sts.AssumeRoleInput) bool {
+		return aws.ToString(input.RoleArn) == "arn:aws:iam::123456789012:role/test-role"
+	})).Return((*sts.AssumeRoleOutput)(nil), errors.New("access denied")).Once()
+	mockSTSClient.On("AssumeRole", mock.Anything, mock.MatchedBy(func(input *sts.AssumeRoleInput) bool {
+		return aws.ToString(input.RoleArn) == "arn:aws:iam::123456789012:role/SecurityAuditRole"
+	})).Return(&sts.AssumeRoleOutput{
+		Credentials: &types.Credentials{AccessKeyId: aws.String("access-key-id"), Expiration: &expiration},
+	}, nil).Twice()
+
+	creds, err := manager.CredentialsFor(context.TODO(), "123456789012", "us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "access-key-id", creds.AccessKeyID)
+	assert.Equal(t, "SecurityAuditRole", manager.RoleFor("123456789012"))
+
+	// The role that was assumed before is tried first in the next region
+	_, err = manager.CredentialsFor(context.TODO(), "123456789012", "us-west-2")
+	assert.NoError(t, err)
+	mockSTSClient.AssertExpectations(t)
+
+	mockSTSClient.On("AssumeRole", mock.Anything, mock.Anything).Return((*sts.AssumeRoleOutput)(nil), errors.New("access denied"))
+	_, err = manager.CredentialsFor(context.TODO(), "210987654321", "us-east-1")
+	assert.EqualError(t, err, "failed to assume role: test-role: access denied\nSecurityAuditRole: access denied")
+	assert.Empty(t, manager.RoleFor("210987654321"))
+}
+
+func TestCredentialsManager_awsRoleArnWithPartition(t *testing.T) {
+	manager := NewCredentialsManager("test-role", nil, WithPartition("aws-us-gov")).(*credentialsManager)
 
-	manager = NewCredentialsManager("", nil).(*credentialsManager)
-	roleArn = manager.awsRoleArn(accountId)
-	assert.Equal(t, "", roleArn)
+	assert.Equal(t, "arn:aws-us-gov:iam::123456789012:role/test-role", manager.awsRoleArn("123456789012", "test-role"))
 }
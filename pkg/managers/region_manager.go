@@ -10,11 +10,16 @@ import (
 
 type RegionManager struct {
 	ec2Client interfaces.EC2Client
+	// ClientFactory creates the EC2 client that describes the regions of an account.
+	ClientFactory func(cfg aws.Config) interfaces.EC2Client
 }
 
 func NewRegionManager(ec2Client interfaces.EC2Client) interfaces.RegionsManager {
 	return &RegionManager{
 		ec2Client: ec2Client,
+		ClientFactory: func(cfg aws.Config) interfaces.EC2Client {
+			return ec2.NewFromConfig(cfg)
+		},
 	}
 }
 
@@ -35,4 +40,24 @@ func (rf *RegionManager) GetRegions(ctx context.Context, cfg aws.Config, specifi
 		regions = append(regions, aws.ToString(region.RegionName))
 	}
 	return regions, nil
-}
\ No newline at end of file
+}
+
+// AccountRegions describes every region of the partition, including the ones
+// the account has not opted in to, and returns whether each is enabled.
+func (rf *RegionManager) AccountRegions(ctx context.Context, cfg aws.Config) (map[string]string, error) {
+	input := &ec2.DescribeRegionsInput{AllRegions: aws.Bool(true)}
+	resp, err := rf.ClientFactory(cfg).DescribeRegions(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]string{}
+	for _, region := range resp.Regions {
+		status := interfaces.RegionEnabled
+		if aws.ToString(region.OptInStatus) == "not-opted-in" {
+			status = interfaces.RegionDisabled
+		}
+		statuses[aws.ToString(region.RegionName)] = status
+	}
+	return statuses, nil
+}
@@ -1,6 +1,7 @@
 package managers
 
 import (
+	"aws-resource-discovery/pkg/interfaces"
 	"aws-resource-discovery/pkg/mocks"
 	"context"
 	"errors"
@@ -56,3 +57,44 @@ func TestRegionManager_GetRegions(t *testing.T) {
 		mockLogger.AssertExpectations(t)
 	})
 }
+
+func TestRegionManager_AccountRegions(t *testing.T) {
+	mockEC2Client := new(mocks.MockEC2Client)
+	manager := NewRegionManager(nil).(*RegionManager)
+	manager.ClientFactory = func(cfg aws.Config) interfaces.EC2Client {
+		return mockEC2Client
+	}
+
+	ctx := context.TODO()
+	cfg := aws.Config{}
+
+	t.Run("Region statuses", func(t *testing.T) {
+		mockEC2Client.On("DescribeRegions", mock.Anything, &ec2.DescribeRegionsInput{AllRegions: aws.Bool(true)}).Return(&ec2.DescribeRegionsOutput{
+			Regions: []types.Region{
+				{RegionName: aws.String("us-east-1"), OptInStatus: aws.String("opt-in-not-required")},
+				{RegionName: aws.String("af-south-1"), OptInStatus: aws.String("opted-in")},
+				{RegionName: aws.String("me-south-1"), OptInStatus: aws.String("not-opted-in")},
+			},
+		}, nil).Once()
+
+		statuses, err := manager.AccountRegions(ctx, cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"us-east-1":  interfaces.RegionEnabled,
+			"af-south-1": interfaces.RegionEnabled,
+			"me-south-1": interfaces.RegionDisabled,
+		}, statuses)
+
+		mockEC2Client.AssertExpectations(t)
+	})
+
+	t.Run("Describe regions error", func(t *testing.T) {
+		mockEC2Client.On("DescribeRegions", mock.Anything, mock.Anything).Return(nil, errors.New("access denied")).Once()
+
+		statuses, err := manager.AccountRegions(ctx, cfg)
+		assert.Error(t, err)
+		assert.Nil(t, statuses)
+
+		mockEC2Client.AssertExpectations(t)
+	})
+}
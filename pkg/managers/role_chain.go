@@ -0,0 +1,27 @@
+package managers
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+)
+
+// ChainRoles returns a copy of cfg whose credentials are obtained by assuming
+// every role of the chain in turn, each with the credentials of the previous
+// hop. Every hop is cached and refreshed separately before it expires.
+func ChainRoles(cfg aws.Config, chain []config.ChainedRole, clientFactory func(cfg aws.Config) stscreds.AssumeRoleAPIClient) aws.Config {
+	chained := cfg.Copy()
+	for i, role := range chain {
+		provider := stscreds.NewAssumeRoleProvider(clientFactory(chained), role.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = fmt.Sprintf("rc-aws-scan-chain-%d", i+1)
+			if role.ExternalId != "" {
+				o.ExternalID = aws.String(role.ExternalId)
+			}
+		})
+		chained = chained.Copy()
+		chained.Credentials = aws.NewCredentialsCache(provider)
+	}
+	return chained
+}
@@ -0,0 +1,56 @@
+package managers
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/mocks"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChainRoles(t *testing.T) {
+	expiration := time.Now().Add(1 * time.Hour)
+	hubClient := new(mocks.MockSTSClient)
+	scanClient := new(mocks.MockSTSClient)
+	hubClient.On("AssumeRole", mock.Anything, mock.MatchedBy(func(input *sts.AssumeRoleInput) bool {
+		return aws.ToString(input.RoleArn) == "arn:aws:iam::111111111111:role/hub" &&
+			aws.ToString(input.ExternalId) == "hub-external-id" &&
+			aws.ToString(input.RoleSessionName) == "rc-aws-scan-chain-1"
+	})).Return(&sts.AssumeRoleOutput{
+		Credentials: &types.Credentials{AccessKeyId: aws.String("hub"), SecretAccessKey: aws.String("secret"), SessionToken: aws.String("token"), Expiration: &expiration},
+	}, nil).Once()
+	scanClient.On("AssumeRole", mock.Anything, mock.MatchedBy(func(input *sts.AssumeRoleInput) bool {
+		return aws.ToString(input.RoleArn) == "arn:aws:iam::222222222222:role/scanner" && input.ExternalId == nil
+	})).Return(&sts.AssumeRoleOutput{
+		Credentials: &types.Credentials{AccessKeyId: aws.String("scanner"), SecretAccessKey: aws.String("secret"), SessionToken: aws.String("token"), Expiration: &expiration},
+	}, nil).Once()
+
+	configs := []aws.Config{}
+	clients := []stscreds.AssumeRoleAPIClient{hubClient, scanClient}
+	chained := ChainRoles(aws.Config{Region: "us-east-1"}, []config.ChainedRole{
+		{RoleArn: "arn:aws:iam::111111111111:role/hub", ExternalId: "hub-external-id"},
+		{RoleArn: "arn:aws:iam::222222222222:role/scanner"},
+	}, func(cfg aws.Config) stscreds.AssumeRoleAPIClient {
+		configs = append(configs, cfg)
+		return clients[len(configs)-1]
+	})
+
+	creds, err := chained.Credentials.Retrieve(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "scanner", creds.AccessKeyID)
+	assert.Equal(t, "us-east-1", chained.Region)
+
+	// The second hop is assumed with the credentials of the first
+	hubCreds, err := configs[1].Credentials.Retrieve(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "hub", hubCreds.AccessKeyID)
+	hubClient.AssertExpectations(t)
+	scanClient.AssertExpectations(t)
+}
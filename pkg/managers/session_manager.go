@@ -3,23 +3,25 @@ package managers
 import (
 	"aws-resource-discovery/pkg/config"
 	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/utils"
 	"context"
 	"fmt"
 	"log"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-
-	aws_conf "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type sessionManager struct {
 	Client interfaces.STSClient
+	// Retries is the retry configuration of the clients of the session.
+	Retries *utils.Retries
 }
 
-func NewSessionManager(client interfaces.STSClient) interfaces.SessionManager {
+func NewSessionManager(client interfaces.STSClient, retries *utils.Retries) interfaces.SessionManager {
 	return &sessionManager{
-		Client: client,
+		Client:  client,
+		Retries: retries,
 	}
 }
 
@@ -65,7 +67,7 @@ func (sm *sessionManager) AssumeRoleIfNeeded(ctx context.Context, cfg aws.Config
 }
 
 func (sm *sessionManager) InitializeSessionAndCredentials(ctx context.Context, config config.Config, logger interfaces.Logger) (aws.Config, aws.Credentials) {
-	cfg, err := aws_conf.LoadDefaultConfig(ctx, aws_conf.WithRegion(config.Region))
+	cfg, err := utils.LoadAWSConfig(ctx, config.Profile, config.Region, sm.Retries)
 	if err != nil {
 		logger.Logf("Failed to load AWS config: %v", err)
 		return aws.Config{}, aws.Credentials{}
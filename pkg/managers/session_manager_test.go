@@ -16,7 +16,7 @@ import (
 
 func TestSessionManager_AssumeRole(t *testing.T) {
 	mockClient := new(mocks.MockSTSClient)
-	manager := NewSessionManager(mockClient)
+	manager := NewSessionManager(mockClient, nil)
 
 	ctx := context.TODO()
 	roleArn := "arn:aws:iam::123456789012:role/test-role"
@@ -46,7 +46,7 @@ func TestSessionManager_AssumeRole(t *testing.T) {
 
 func TestSessionManager_AssumeRoleIfNeeded(t *testing.T) {
 	mockClient := new(mocks.MockSTSClient)
-	manager := NewSessionManager(mockClient)
+	manager := NewSessionManager(mockClient, nil)
 
 	ctx := context.TODO()
 	cfg := aws.Config{}
@@ -96,7 +96,7 @@ func TestSessionManager_AssumeRoleIfNeeded(t *testing.T) {
 func TestSessionManager_InitializeSessionAndCredentials(t *testing.T) {
 	mockClient := new(mocks.MockSTSClient)
 	mockLogger := new(mocks.MockLogger)
-	manager := NewSessionManager(mockClient)
+	manager := NewSessionManager(mockClient, nil)
 
 	ctx := context.TODO()
 	accountId := "123456789012"
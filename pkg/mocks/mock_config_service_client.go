@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockConfigServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockConfigServiceClient) SelectAggregateResourceConfig(ctx context.Context, params *configservice.SelectAggregateResourceConfigInput, optFns ...func(*configservice.Options)) (*configservice.SelectAggregateResourceConfigOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*configservice.SelectAggregateResourceConfigOutput), args.Error(1)
+}
+
+func (m *MockConfigServiceClient) DescribeConfigurationAggregatorSourcesStatus(ctx context.Context, params *configservice.DescribeConfigurationAggregatorSourcesStatusInput, optFns ...func(*configservice.Options)) (*configservice.DescribeConfigurationAggregatorSourcesStatusOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*configservice.DescribeConfigurationAggregatorSourcesStatusOutput), args.Error(1)
+}
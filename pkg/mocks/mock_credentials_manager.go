@@ -15,3 +15,8 @@ func (m *MockCredentialsManager) CredentialsFor(ctx context.Context, accountId,
 	args := m.Called(ctx, accountId, region)
 	return args.Get(0).(aws.Credentials), args.Error(1)
 }
+
+func (m *MockCredentialsManager) RoleFor(accountId string) string {
+	args := m.Called(accountId)
+	return args.String(0)
+}
@@ -11,9 +11,20 @@ type MockEC2Client struct {
 	mock.Mock
 }
 
+func (m *MockEC2Client) DescribeAvailabilityZones(ctx context.Context, params *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) != nil {
+		return args.Get(0).(*ec2.DescribeAvailabilityZonesOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockEC2Client) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
 	args := m.Called(ctx, params)
-	return args.Get(0).(*ec2.DescribeInstancesOutput), args.Error(1)
+	if args.Get(0) != nil {
+		return args.Get(0).(*ec2.DescribeInstancesOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
 func (m *MockEC2Client) DescribeRegions(ctx context.Context, input *ec2.DescribeRegionsInput, opts ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
@@ -23,3 +34,11 @@ func (m *MockEC2Client) DescribeRegions(ctx context.Context, input *ec2.Describe
 	}
 	return nil, args.Error(1)
 }
+
+func (m *MockEC2Client) DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, opts ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) != nil {
+		return args.Get(0).(*ec2.DescribeVolumesOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
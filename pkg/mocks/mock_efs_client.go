@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEFSClient struct {
+	mock.Mock
+}
+
+func (m *MockEFSClient) DescribeFileSystems(ctx context.Context, params *efs.DescribeFileSystemsInput, optFns ...func(*efs.Options)) (*efs.DescribeFileSystemsOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) != nil {
+		return args.Get(0).(*efs.DescribeFileSystemsOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
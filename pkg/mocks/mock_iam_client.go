@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIAMClient struct {
+	mock.Mock
+}
+
+func (m *MockIAMClient) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) != nil {
+		return args.Get(0).(*iam.SimulatePrincipalPolicyOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIAMClient) GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) != nil {
+		return args.Get(0).(*iam.GetRoleOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
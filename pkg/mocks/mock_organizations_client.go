@@ -28,6 +28,46 @@ func (m *MockOrganizationsClient) ListAccounts(ctx context.Context, params *orga
 	return args.Get(0).(*organizations.ListAccountsOutput), args.Error(1)
 }
 
+func (m *MockOrganizationsClient) ListParents(ctx context.Context, params *organizations.ListParentsInput, optFns ...func(*organizations.Options)) (*organizations.ListParentsOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListParentsOutput), args.Error(1)
+}
+
+func (m *MockOrganizationsClient) DescribeOrganizationalUnit(ctx context.Context, params *organizations.DescribeOrganizationalUnitInput, optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationalUnitOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.DescribeOrganizationalUnitOutput), args.Error(1)
+}
+
+func (m *MockOrganizationsClient) ListRoots(ctx context.Context, params *organizations.ListRootsInput, optFns ...func(*organizations.Options)) (*organizations.ListRootsOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListRootsOutput), args.Error(1)
+}
+
+func (m *MockOrganizationsClient) ListOrganizationalUnitsForParent(ctx context.Context, params *organizations.ListOrganizationalUnitsForParentInput, optFns ...func(*organizations.Options)) (*organizations.ListOrganizationalUnitsForParentOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListOrganizationalUnitsForParentOutput), args.Error(1)
+}
+
+func (m *MockOrganizationsClient) ListAccountsForParent(ctx context.Context, params *organizations.ListAccountsForParentInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsForParentOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListAccountsForParentOutput), args.Error(1)
+}
+
 type MockOrgDetector struct {
 	mock.Mock
 }
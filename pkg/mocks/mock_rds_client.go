@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRDSClient struct {
+	mock.Mock
+}
+
+func (m *MockRDSClient) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) != nil {
+		return args.Get(0).(*rds.DescribeDBInstancesOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -16,3 +16,11 @@ func (m *MockRegionsManager) GetRegions(ctx context.Context, cfg aws.Config, spe
 	args := m.Called(ctx, cfg, specifiedRegion, logger)
 	return args.Get(0).([]string), args.Error(1)
 }
+
+func (m *MockRegionsManager) AccountRegions(ctx context.Context, cfg aws.Config) (map[string]string, error) {
+	args := m.Called(ctx, cfg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
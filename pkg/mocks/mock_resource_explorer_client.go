@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockResourceExplorerClient struct {
+	mock.Mock
+}
+
+func (m *MockResourceExplorerClient) Search(ctx context.Context, params *resourceexplorer2.SearchInput, optFns ...func(*resourceexplorer2.Options)) (*resourceexplorer2.SearchOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*resourceexplorer2.SearchOutput), args.Error(1)
+}
+
+func (m *MockResourceExplorerClient) ListIndexesForMembers(ctx context.Context, params *resourceexplorer2.ListIndexesForMembersInput, optFns ...func(*resourceexplorer2.Options)) (*resourceexplorer2.ListIndexesForMembersOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*resourceexplorer2.ListIndexesForMembersOutput), args.Error(1)
+}
+
+type MockCountSource struct {
+	mock.Mock
+}
+
+func (m *MockCountSource) Name() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockCountSource) Count(accountId, region, resourceType string) (int, bool) {
+	args := m.Called(accountId, region, resourceType)
+	return args.Int(0), args.Bool(1)
+}
@@ -19,6 +19,14 @@ func (m *MockSTSClient) AssumeRole(ctx context.Context, params *sts.AssumeRoleIn
 	return args.Get(0).(*sts.AssumeRoleOutput), args.Error(1)
 }
 
+func (m *MockSTSClient) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sts.GetCallerIdentityOutput), args.Error(1)
+}
+
 type MockSessionManager struct {
 	mock.Mock
 }
@@ -0,0 +1,208 @@
+package preflight
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/counter"
+	"aws-resource-discovery/pkg/utils"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecrpublic"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+)
+
+// placeholder names the resources of the follow-up calls of the checks, which
+// need not exist: the calls are authorized before the resource is looked up.
+const placeholder = "preflight"
+
+// Check is one minimal call to the API a counter needs, followed by the other
+// calls of the counter when it makes several.
+type Check struct {
+	// Name labels the check in the matrix, e.g. "ECS".
+	Name string
+	// Action is the IAM action of the call, e.g. "ecs:ListClusters".
+	Action string
+	// Permissions are every IAM action of the counter the check stands for.
+	Permissions []string
+	Probe       func(ctx context.Context, cfg aws.Config) error
+}
+
+// Checks returns the checks of the counters enabled by the configuration.
+func Checks(userConfig config.Config, partition string) []Check {
+	checks := []Check{
+		cloudControlCheck("S3", "s3:ListAllMyBuckets", "AWS::S3::Bucket"),
+	}
+	if utils.HasECRPublic(partition) {
+		checks = append(checks, Check{Name: "ECRPublic", Action: "ecr-public:DescribeRepositories", Permissions: counter.Permissions["AWS::ECR::PublicRepository"], Probe: func(ctx context.Context, cfg aws.Config) error {
+			// ECR Public is only served from the global region
+			client := ecrpublic.NewFromConfig(cfg, func(o *ecrpublic.Options) {
+				o.Region = utils.GlobalRegion(partition)
+			})
+			if _, err := client.DescribeRepositories(ctx, &ecrpublic.DescribeRepositoriesInput{MaxResults: aws.Int32(1)}); err != nil {
+				return err
+			}
+			return firstDenied(func() error {
+				_, err := client.DescribeImages(ctx, &ecrpublic.DescribeImagesInput{RepositoryName: aws.String(placeholder), MaxResults: aws.Int32(1)})
+				return err
+			})
+		}})
+	}
+
+	if userConfig.CountStrategy == config.StrategyCloudControl {
+		checks = append(checks,
+			cloudControlCheck("EC2", "ec2:DescribeInstances", "AWS::EC2::Instance"),
+			cloudControlCheck("DynamoDB", "dynamodb:ListTables", "AWS::DynamoDB::Table"),
+			cloudControlCheck("EBS", "ec2:DescribeVolumes", "AWS::EC2::Volume"),
+			cloudControlCheck("EFS", "elasticfilesystem:DescribeFileSystems", "AWS::EFS::FileSystem"),
+			cloudControlCheck("Lambda", "lambda:ListFunctions", "AWS::Lambda::Function"),
+			cloudControlCheck("RDS", "rds:DescribeDBInstances", "AWS::RDS::DBInstance"))
+	} else {
+		checks = append(checks,
+			Check{Name: "EC2", Action: "ec2:DescribeInstances", Permissions: counter.Permissions["AWS::EC2::Instance"], Probe: func(ctx context.Context, cfg aws.Config) error {
+				_, err := ec2.NewFromConfig(cfg).DescribeInstances(ctx, &ec2.DescribeInstancesInput{MaxResults: aws.Int32(5)})
+				return err
+			}},
+			Check{Name: "DynamoDB", Action: "dynamodb:ListTables", Permissions: counter.Permissions["AWS::DynamoDB::Table"], Probe: func(ctx context.Context, cfg aws.Config) error {
+				_, err := dynamodb.NewFromConfig(cfg).ListTables(ctx, &dynamodb.ListTablesInput{Limit: aws.Int32(1)})
+				return err
+			}},
+			Check{Name: "EBS", Action: "ec2:DescribeVolumes", Permissions: counter.Permissions["AWS::EC2::Volume"], Probe: func(ctx context.Context, cfg aws.Config) error {
+				_, err := ec2.NewFromConfig(cfg).DescribeVolumes(ctx, &ec2.DescribeVolumesInput{MaxResults: aws.Int32(5)})
+				return err
+			}},
+			Check{Name: "EFS", Action: "elasticfilesystem:DescribeFileSystems", Permissions: counter.Permissions["AWS::EFS::FileSystem"], Probe: func(ctx context.Context, cfg aws.Config) error {
+				_, err := efs.NewFromConfig(cfg).DescribeFileSystems(ctx, &efs.DescribeFileSystemsInput{MaxItems: aws.Int32(1)})
+				return err
+			}},
+			Check{Name: "Lambda", Action: "lambda:ListFunctions", Permissions: counter.Permissions["AWS::Lambda::Function"], Probe: func(ctx context.Context, cfg aws.Config) error {
+				_, err := lambda.NewFromConfig(cfg).ListFunctions(ctx, &lambda.ListFunctionsInput{MaxItems: aws.Int32(1)})
+				return err
+			}},
+			Check{Name: "RDS", Action: "rds:DescribeDBInstances", Permissions: counter.Permissions["AWS::RDS::DBInstance"], Probe: func(ctx context.Context, cfg aws.Config) error {
+				_, err := rds.NewFromConfig(cfg).DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{MaxRecords: aws.Int32(20)})
+				return err
+			}})
+	}
+
+	checks = append(checks,
+		Check{Name: "ECR", Action: "ecr:DescribeRepositories", Permissions: counter.Permissions["AWS::ECR::Repository"], Probe: func(ctx context.Context, cfg aws.Config) error {
+			client := ecr.NewFromConfig(cfg)
+			if _, err := client.DescribeRepositories(ctx, &ecr.DescribeRepositoriesInput{MaxResults: aws.Int32(1)}); err != nil {
+				return err
+			}
+			return firstDenied(func() error {
+				_, err := client.ListImages(ctx, &ecr.ListImagesInput{RepositoryName: aws.String(placeholder), MaxResults: aws.Int32(1)})
+				return err
+			})
+		}},
+		Check{Name: "ECS", Action: "ecs:ListClusters", Permissions: counter.Permissions["AWS::ECS::Cluster"], Probe: func(ctx context.Context, cfg aws.Config) error {
+			client := ecs.NewFromConfig(cfg)
+			if _, err := client.ListClusters(ctx, &ecs.ListClustersInput{MaxResults: aws.Int32(1)}); err != nil {
+				return err
+			}
+			return firstDenied(func() error {
+				_, err := client.ListServices(ctx, &ecs.ListServicesInput{Cluster: aws.String(placeholder), MaxResults: aws.Int32(1)})
+				return err
+			}, func() error {
+				_, err := client.DescribeServices(ctx, &ecs.DescribeServicesInput{Cluster: aws.String(placeholder), Services: []string{placeholder}})
+				return err
+			}, func() error {
+				_, err := client.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{TaskDefinition: aws.String(placeholder)})
+				return err
+			})
+		}},
+		Check{Name: "EKS", Action: "eks:ListClusters", Permissions: counter.Permissions["AWS::EKS::Cluster"], Probe: func(ctx context.Context, cfg aws.Config) error {
+			_, err := eks.NewFromConfig(cfg).ListClusters(ctx, &eks.ListClustersInput{MaxResults: aws.Int32(1)})
+			return err
+		}},
+		Check{Name: "AutoScaling", Action: "autoscaling:DescribeAutoScalingGroups", Permissions: counter.Permissions["AWS::AutoScaling::AutoScalingGroup"], Probe: func(ctx context.Context, cfg aws.Config) error {
+			_, err := autoscaling.NewFromConfig(cfg).DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{MaxRecords: aws.Int32(1)})
+			return err
+		}})
+
+	if userConfig.Reconcile && userConfig.CountStrategy != config.StrategyCloudControl {
+		checks = append(checks, cloudControlCheck("CloudControl", counter.CloudControlPermission, "AWS::EC2::Instance"))
+	}
+	if len(userConfig.IncludeTags) > 0 || len(userConfig.ExcludeTags) > 0 || userConfig.GroupByTag != "" {
+		checks = append(checks, Check{Name: "Tags", Action: counter.TagsPermission, Permissions: []string{counter.TagsPermission}, Probe: func(ctx context.Context, cfg aws.Config) error {
+			_, err := resourcegroupstaggingapi.NewFromConfig(cfg).GetResources(ctx, &resourcegroupstaggingapi.GetResourcesInput{ResourcesPerPage: aws.Int32(1)})
+			return err
+		}})
+	}
+	if userConfig.Lookback > 0 {
+		checks = append(checks, Check{Name: "CloudWatch", Action: counter.HistoryPermission, Permissions: []string{counter.HistoryPermission}, Probe: func(ctx context.Context, cfg aws.Config) error {
+			end := time.Now()
+			_, err := cloudwatch.NewFromConfig(cfg).GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+				StartTime: aws.Time(end.Add(-time.Hour)),
+				EndTime:   aws.Time(end),
+				MetricDataQueries: []cwtypes.MetricDataQuery{{
+					Id: aws.String("preflight"),
+					MetricStat: &cwtypes.MetricStat{
+						Metric: &cwtypes.Metric{Namespace: aws.String("AWS/EC2"), MetricName: aws.String("CPUUtilization")},
+						Period: aws.Int32(3600),
+						Stat:   aws.String("Average"),
+					},
+				}},
+			})
+			return err
+		}})
+	}
+	return checks
+}
+
+// Actions returns every IAM action the counters enabled by the configuration
+// need, in order. They are the permissions of the checks of the configuration.
+func Actions(userConfig config.Config, partition string) []string {
+	seen := map[string]bool{}
+	actions := []string{}
+	for _, check := range Checks(userConfig, partition) {
+		for _, action := range check.Permissions {
+			if !seen[action] {
+				seen[action] = true
+				actions = append(actions, action)
+			}
+		}
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// cloudControlCheck lists one resource of a type with CloudControl, which
+// needs both cloudformation:ListResources and the permissions of the counter
+// of the type.
+func cloudControlCheck(name, action, typeName string) Check {
+	permissions := append([]string{counter.CloudControlPermission}, counter.Permissions[typeName]...)
+	return Check{Name: name, Action: action, Permissions: permissions, Probe: func(ctx context.Context, cfg aws.Config) error {
+		_, err := cloudcontrol.NewFromConfig(cfg).ListResources(ctx, &cloudcontrol.ListResourcesInput{
+			TypeName:   aws.String(typeName),
+			MaxResults: aws.Int32(1),
+		})
+		return err
+	}}
+}
+
+// firstDenied makes the follow-up calls of a check on the placeholder
+// resources and returns the first access denied error. Any other error, such
+// as a missing cluster, shows the call was allowed.
+func firstDenied(calls ...func() error) error {
+	for _, call := range calls {
+		if err := call(); utils.IsAccessDenied(err) {
+			return err
+		}
+	}
+	return nil
+}
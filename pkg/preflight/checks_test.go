@@ -0,0 +1,64 @@
+package preflight
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/counter"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func checkNames(checks []Check) []string {
+	names := []string{}
+	for _, check := range checks {
+		names = append(names, check.Name)
+	}
+	return names
+}
+
+func TestChecks(t *testing.T) {
+	checks := Checks(config.Config{CountStrategy: config.StrategyNative}, "aws")
+	assert.Equal(t, []string{"S3", "ECRPublic", "EC2", "DynamoDB", "EBS", "EFS", "Lambda", "RDS", "ECR", "ECS", "EKS", "AutoScaling"}, checkNames(checks))
+	for _, check := range checks {
+		assert.NotEmpty(t, check.Action)
+		assert.Contains(t, check.Permissions, check.Action)
+		assert.NotNil(t, check.Probe)
+	}
+
+	checks = Checks(config.Config{
+		CountStrategy: config.StrategyNative,
+		Reconcile:     true,
+		GroupByTag:    "cost-center",
+		Lookback:      24 * time.Hour,
+	}, "aws-us-gov")
+	assert.NotContains(t, checkNames(checks), "ECRPublic")
+	assert.Subset(t, checkNames(checks), []string{"CloudControl", "Tags", "CloudWatch"})
+}
+
+func TestActions(t *testing.T) {
+	actions := Actions(config.Config{GroupByTag: "cost-center"}, "aws")
+	assert.Contains(t, actions, "ecr-public:DescribeImages")
+	assert.Contains(t, actions, "tag:GetResources")
+	assert.NotContains(t, actions, "cloudwatch:GetMetricData")
+	assert.IsIncreasing(t, actions)
+
+	// The actions are the permissions of the enabled counters
+	for resourceType, permissions := range counter.Permissions {
+		if resourceType != "AWS::ECR::PublicRepository" {
+			assert.Subset(t, Actions(config.Config{}, "aws-cn"), permissions, resourceType)
+		}
+	}
+
+	assert.NotContains(t, Actions(config.Config{}, "aws-cn"), "ecr-public:DescribeRepositories")
+}
+
+func TestFirstDenied(t *testing.T) {
+	notFound := &smithy.GenericAPIError{Code: "ClusterNotFoundException"}
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform: ecs:DescribeTaskDefinition"}
+	allowed := func() error { return nil }
+
+	assert.NoError(t, firstDenied(allowed, func() error { return notFound }))
+	assert.Equal(t, denied, firstDenied(allowed, func() error { return notFound }, func() error { return denied }))
+}
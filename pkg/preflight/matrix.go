@@ -0,0 +1,63 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// PrintMatrix prints the status of every check in every account, followed by
+// the IAM actions missing in each account.
+func PrintMatrix(results []Result, checks []Check) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	headers := []interface{}{"Account", "AssumeRole"}
+	for _, check := range checks {
+		headers = append(headers, check.Name)
+	}
+	fmt.Println()
+	tbl := table.New(headers...).WithPadding(3)
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, result := range results {
+		row := []interface{}{result.AccountId, result.AssumeRole}
+		for _, check := range checks {
+			row = append(row, result.Statuses[check.Name])
+		}
+		tbl.AddRow(row...)
+	}
+	tbl.Print()
+
+	missing := table.New("Account", "Missing IAM actions").WithPadding(3)
+	missing.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	failed := 0
+	for _, result := range results {
+		if len(result.Missing) > 0 {
+			missing.AddRow(result.AccountId, strings.Join(result.Missing, ", "))
+		}
+		if !result.Passed() {
+			failed++
+		}
+	}
+	if failed == 0 {
+		fmt.Printf("\nPreflight passed in %d accounts.\n", len(results))
+		return
+	}
+	fmt.Printf("\nPreflight failed in %d of %d accounts.\n\n", failed, len(results))
+	missing.Print()
+	printErrors(results, checks)
+}
+
+// printErrors prints the errors of the checks that failed for another reason
+// than a missing permission.
+func printErrors(results []Result, checks []Check) {
+	for _, result := range results {
+		for _, check := range checks {
+			if result.Statuses[check.Name] == StatusError {
+				fmt.Printf("%s %s: %v\n", result.AccountId, check.Name, result.Errors[check.Name])
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package preflight
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/rodaine/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func captureOutput(f func()) string {
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestPrintMatrix(t *testing.T) {
+	checks := []Check{{Name: "ECS", Action: "ecs:ListClusters"}, {Name: "EKS", Action: "eks:ListClusters"}}
+	results := []Result{
+		{AccountId: "111111111111", AssumeRole: StatusPass, Statuses: map[string]string{"ECS": StatusPass, "EKS": StatusPass}, Errors: map[string]error{}},
+		{AccountId: "222222222222", AssumeRole: StatusPass, Statuses: map[string]string{"ECS": StatusDenied, "EKS": StatusError},
+			Missing: []string{"ecs:ListClusters"},
+			Errors:  map[string]error{"ECS": errors.New("denied"), "EKS": errors.New("connection reset")}},
+	}
+
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	output := captureOutput(func() { PrintMatrix(results, checks) })
+
+	assert.Contains(t, buf.String(), "AssumeRole")
+	assert.Contains(t, buf.String(), "denied")
+	assert.Contains(t, buf.String(), "ecs:ListClusters")
+	assert.Contains(t, output, "Preflight failed in 1 of 2 accounts.")
+	assert.Contains(t, output, "222222222222 EKS: connection reset")
+
+	output = captureOutput(func() { PrintMatrix(results[:1], checks) })
+	assert.Contains(t, output, "Preflight passed in 1 accounts.")
+}
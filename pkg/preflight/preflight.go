@@ -0,0 +1,141 @@
+package preflight
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/utils"
+	"context"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// Statuses of a check in an account.
+const (
+	StatusPass    = "pass"
+	StatusDenied  = "denied"
+	StatusError   = "error"
+	StatusSkipped = "skipped"
+)
+
+// assumeRoleAction is reported as missing when the role cannot be assumed.
+const assumeRoleAction = "sts:AssumeRole"
+
+// deniedActionPattern finds the action in messages such as "is not authorized
+// to perform: ecs:ListClusters on resource: *".
+var deniedActionPattern = regexp.MustCompile(`perform: ([a-zA-Z0-9-]+:[a-zA-Z0-9]+)`)
+
+// Result holds the outcome of the checks in one account.
+type Result struct {
+	AccountId string
+	// AssumeRole is the status of the role assumption in the account.
+	AssumeRole string
+	// Statuses holds the status of every check by name.
+	Statuses map[string]string
+	// Missing lists the IAM actions that were denied.
+	Missing []string
+	// Errors holds the error of every check that did not pass by name.
+	Errors map[string]error
+}
+
+// Passed reports whether the role was assumed and every check passed.
+func (r Result) Passed() bool {
+	return r.AssumeRole == StatusPass && len(r.Errors) == 0
+}
+
+// Preflight assumes the scanning role in accounts and makes one call per check
+// in one region, before any counting begins.
+type Preflight struct {
+	CredentialsManager interfaces.CredentialsManager
+	// Config holds the caller credentials.
+	Config aws.Config
+	// CallerAccountId is checked with the caller credentials instead of assuming a role.
+	CallerAccountId string
+	Region          string
+	Checks          []Check
+}
+
+// Run checks every account in turn.
+func (p *Preflight) Run(ctx context.Context, accountIds []string) []Result {
+	results := make([]Result, 0, len(accountIds))
+	for _, accountId := range accountIds {
+		results = append(results, p.check(ctx, accountId))
+	}
+	return results
+}
+
+// check runs the checks in an account.
+func (p *Preflight) check(ctx context.Context, accountId string) Result {
+	result := Result{
+		AccountId: accountId,
+		Statuses:  map[string]string{},
+		Errors:    map[string]error{},
+	}
+
+	cfg := p.Config.Copy()
+	cfg.Region = p.Region
+	if accountId != p.CallerAccountId {
+		creds, err := p.CredentialsManager.CredentialsFor(ctx, accountId, p.Region)
+		if err != nil {
+			result.AssumeRole = StatusDenied
+			result.Missing = append(result.Missing, assumeRoleAction)
+			result.Errors[assumeRoleAction] = err
+			for _, check := range p.Checks {
+				result.Statuses[check.Name] = StatusSkipped
+			}
+			return result
+		}
+		cfg.Credentials = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(
+			creds.AccessKeyID,
+			creds.SecretAccessKey,
+			creds.SessionToken,
+		))
+	}
+	result.AssumeRole = StatusPass
+
+	for _, check := range p.Checks {
+		err := check.Probe(ctx, cfg)
+		switch {
+		case err == nil:
+			result.Statuses[check.Name] = StatusPass
+		case utils.IsAccessDenied(err):
+			result.Statuses[check.Name] = StatusDenied
+			result.Missing = append(result.Missing, deniedAction(err, check.Action))
+			result.Errors[check.Name] = err
+		default:
+			result.Statuses[check.Name] = StatusError
+			result.Errors[check.Name] = err
+		}
+	}
+	return result
+}
+
+// Sample returns the caller account followed by the first size other accounts,
+// or by every other account when size is not positive.
+func Sample(accountIds []string, callerAccountId string, size int) []string {
+	sample := []string{}
+	if callerAccountId != "" {
+		sample = append(sample, callerAccountId)
+	}
+	members := 0
+	for _, accountId := range accountIds {
+		if accountId == callerAccountId {
+			continue
+		}
+		if size > 0 && members == size {
+			break
+		}
+		sample = append(sample, accountId)
+		members++
+	}
+	return sample
+}
+
+// deniedAction returns the action named in an access denied error, or the
+// action of the check when the message does not name one.
+func deniedAction(err error, action string) string {
+	if match := deniedActionPattern.FindStringSubmatch(err.Error()); match != nil {
+		return match[1]
+	}
+	return action
+}
@@ -0,0 +1,79 @@
+package preflight
+
+import (
+	"aws-resource-discovery/pkg/mocks"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// probeReturning returns a probe that fails with err in accounts whose
+// credentials use the given access key.
+func probeReturning(accessKeyId string, err error) func(ctx context.Context, cfg aws.Config) error {
+	return func(ctx context.Context, cfg aws.Config) error {
+		creds, _ := cfg.Credentials.Retrieve(ctx)
+		if creds.AccessKeyID == accessKeyId {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestPreflight_Run(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "222222222222", "eu-west-1").Return(aws.Credentials{AccessKeyID: "member", SecretAccessKey: "secret"}, nil)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "333333333333", "eu-west-1").Return(aws.Credentials{}, errors.New("test error"))
+
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "User: arn:aws:sts::222222222222:assumed-role/scanner is not authorized to perform: ecs:ListClusters on resource: *"}
+	preflight := &Preflight{
+		CredentialsManager: mockCredentialsManager,
+		Config: aws.Config{Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "caller", SecretAccessKey: "secret"}, nil
+		})},
+		CallerAccountId: "111111111111",
+		Region:          "eu-west-1",
+		Checks: []Check{
+			{Name: "EC2", Action: "ec2:DescribeInstances", Probe: probeReturning("caller", errors.New("connection reset"))},
+			{Name: "ECS", Action: "ecs:ListClusters", Probe: probeReturning("member", denied)},
+		},
+	}
+
+	results := preflight.Run(context.TODO(), []string{"111111111111", "222222222222", "333333333333"})
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, StatusPass, results[0].AssumeRole)
+	assert.Equal(t, map[string]string{"EC2": StatusError, "ECS": StatusPass}, results[0].Statuses)
+	assert.Empty(t, results[0].Missing)
+	assert.False(t, results[0].Passed())
+
+	assert.Equal(t, map[string]string{"EC2": StatusPass, "ECS": StatusDenied}, results[1].Statuses)
+	assert.Equal(t, []string{"ecs:ListClusters"}, results[1].Missing)
+
+	assert.Equal(t, StatusDenied, results[2].AssumeRole)
+	assert.Equal(t, map[string]string{"EC2": StatusSkipped, "ECS": StatusSkipped}, results[2].Statuses)
+	assert.Equal(t, []string{"sts:AssumeRole"}, results[2].Missing)
+	mockCredentialsManager.AssertExpectations(t)
+}
+
+func TestResult_Passed(t *testing.T) {
+	assert.True(t, Result{AssumeRole: StatusPass, Errors: map[string]error{}}.Passed())
+	assert.False(t, Result{AssumeRole: StatusDenied}.Passed())
+}
+
+func TestSample(t *testing.T) {
+	accounts := []string{"222222222222", "111111111111", "333333333333", "444444444444"}
+
+	assert.Equal(t, []string{"111111111111", "222222222222", "333333333333"}, Sample(accounts, "111111111111", 2))
+	assert.Equal(t, []string{"111111111111", "222222222222", "333333333333", "444444444444"}, Sample(accounts, "111111111111", 0))
+	assert.Equal(t, []string{"222222222222"}, Sample(accounts, "", 1))
+}
+
+func TestDeniedAction(t *testing.T) {
+	assert.Equal(t, "cloudformation:ListResources", deniedAction(errors.New("not authorized to perform: cloudformation:ListResources"), "ec2:DescribeInstances"))
+	assert.Equal(t, "ec2:DescribeInstances", deniedAction(errors.New("UnauthorizedOperation: You are not authorized to perform this operation."), "ec2:DescribeInstances"))
+}
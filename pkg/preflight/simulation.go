@@ -0,0 +1,118 @@
+package preflight
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// Simulator simulates the policies of the scanning role of an account with
+// iam:SimulatePrincipalPolicy, which also evaluates the service control
+// policies and permissions boundary that apply to the role.
+type Simulator struct {
+	CredentialsManager interfaces.CredentialsManager
+	ClientFactory      func(cfg aws.Config) interfaces.IAMClient
+	// Config holds the caller credentials.
+	Config aws.Config
+	// CallerAccountId is simulated for the principal of CallerArn with the
+	// caller credentials instead of assuming a role into it.
+	CallerAccountId string
+	CallerArn       string
+	Partition       string
+	Region          string
+	Actions         []string
+}
+
+// Simulate returns the actions the policies deny to the scanning role of an account.
+func (s *Simulator) Simulate(ctx context.Context, accountId string) ([]interfaces.DeniedAction, error) {
+	cfg := s.Config.Copy()
+	cfg.Region = s.Region
+	var policySourceArn string
+	if accountId == s.CallerAccountId {
+		policySourceArn = principalArn(s.CallerArn)
+	} else {
+		creds, err := s.CredentialsManager.CredentialsFor(ctx, accountId, s.Region)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Credentials = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(
+			creds.AccessKeyID,
+			creds.SecretAccessKey,
+			creds.SessionToken,
+		))
+		policySourceArn = fmt.Sprintf("arn:%s:iam::%s:role/%s", s.Partition, accountId, s.CredentialsManager.RoleFor(accountId))
+	}
+	client := s.ClientFactory(cfg)
+	policySourceArn = withRolePath(ctx, client, policySourceArn)
+
+	denied := []interfaces.DeniedAction{}
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(client, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(policySourceArn),
+		ActionNames:     s.Actions,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the policies of %s: %w", policySourceArn, err)
+		}
+		for _, result := range output.EvaluationResults {
+			if result.EvalDecision == types.PolicyEvaluationDecisionTypeAllowed {
+				continue
+			}
+			denied = append(denied, interfaces.DeniedAction{
+				AccountId: accountId,
+				Action:    aws.ToString(result.EvalActionName),
+				DeniedBy:  deniedBy(result),
+				Decision:  string(result.EvalDecision),
+			})
+		}
+	}
+	return denied, nil
+}
+
+// deniedBy returns the type of policy that decided the denial of an action.
+func deniedBy(result types.EvaluationResult) string {
+	if detail := result.OrganizationsDecisionDetail; detail != nil && !detail.AllowedByOrganizations {
+		return interfaces.DeniedBySCP
+	}
+	if detail := result.PermissionsBoundaryDecisionDetail; detail != nil && !detail.AllowedByPermissionsBoundary {
+		return interfaces.DeniedByPermissionBoundary
+	}
+	return interfaces.DeniedByIdentityPolicy
+}
+
+// principalArn returns the IAM ARN of a caller identity, with the role of an
+// assumed role session, e.g. arn:aws:iam::123456789012:role/Admin for
+// arn:aws:sts::123456789012:assumed-role/Admin/session.
+func principalArn(callerArn string) string {
+	parsed, err := arn.Parse(callerArn)
+	if err != nil || parsed.Service != "sts" || !strings.HasPrefix(parsed.Resource, "assumed-role/") {
+		return callerArn
+	}
+	roleName := strings.Split(strings.TrimPrefix(parsed.Resource, "assumed-role/"), "/")[0]
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parsed.Partition, parsed.AccountID, roleName)
+}
+
+// withRolePath returns the ARN of a role with its path, such as
+// /aws-reserved/sso.amazonaws.com/ for the roles of IAM Identity Center, which
+// the ARNs of assumed role sessions leave out. The ARN is returned unchanged
+// when it is not a role or the role cannot be read.
+func withRolePath(ctx context.Context, client interfaces.IAMClient, roleArn string) string {
+	parsed, err := arn.Parse(roleArn)
+	if err != nil || !strings.HasPrefix(parsed.Resource, "role/") {
+		return roleArn
+	}
+	segments := strings.Split(parsed.Resource, "/")
+	output, err := client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(segments[len(segments)-1])})
+	if err != nil || output.Role == nil {
+		return roleArn
+	}
+	return aws.ToString(output.Role.Arn)
+}
@@ -0,0 +1,74 @@
+package preflight
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/mocks"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSimulator_Simulate(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockIAMClient := new(mocks.MockIAMClient)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "222222222222", "us-east-1").Return(aws.Credentials{AccessKeyID: "member", SecretAccessKey: "secret"}, nil)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "333333333333", "us-east-1").Return(aws.Credentials{}, errors.New("test error"))
+	mockCredentialsManager.On("RoleFor", "222222222222").Return("red-canary-resource-discovery-role")
+	mockIAMClient.On("SimulatePrincipalPolicy", mock.Anything, mock.MatchedBy(func(input *iam.SimulatePrincipalPolicyInput) bool {
+		return aws.ToString(input.PolicySourceArn) == "arn:aws:iam::222222222222:role/red-canary-resource-discovery-role"
+	})).Return(&iam.SimulatePrincipalPolicyOutput{
+		EvaluationResults: []types.EvaluationResult{
+			{EvalActionName: aws.String("ec2:DescribeInstances"), EvalDecision: types.PolicyEvaluationDecisionTypeAllowed},
+			{EvalActionName: aws.String("ecs:ListClusters"), EvalDecision: types.PolicyEvaluationDecisionTypeExplicitDeny,
+				OrganizationsDecisionDetail: &types.OrganizationsDecisionDetail{AllowedByOrganizations: false}},
+			{EvalActionName: aws.String("eks:ListClusters"), EvalDecision: types.PolicyEvaluationDecisionTypeImplicitDeny,
+				OrganizationsDecisionDetail:       &types.OrganizationsDecisionDetail{AllowedByOrganizations: true},
+				PermissionsBoundaryDecisionDetail: &types.PermissionsBoundaryDecisionDetail{AllowedByPermissionsBoundary: false}},
+			{EvalActionName: aws.String("tag:GetResources"), EvalDecision: types.PolicyEvaluationDecisionTypeImplicitDeny},
+		},
+	}, nil).Once()
+	mockIAMClient.On("SimulatePrincipalPolicy", mock.Anything, mock.MatchedBy(func(input *iam.SimulatePrincipalPolicyInput) bool {
+		return aws.ToString(input.PolicySourceArn) == "arn:aws:iam::111111111111:role/aws-reserved/sso.amazonaws.com/AWSReservedSSO_Admin_0123456789abcdef"
+	})).Return(nil, errors.New("test error")).Once()
+	// The role of the caller has a path, the role of the member cannot be read
+	mockIAMClient.On("GetRole", mock.Anything, &iam.GetRoleInput{RoleName: aws.String("AWSReservedSSO_Admin_0123456789abcdef")}).Return(&iam.GetRoleOutput{
+		Role: &types.Role{Arn: aws.String("arn:aws:iam::111111111111:role/aws-reserved/sso.amazonaws.com/AWSReservedSSO_Admin_0123456789abcdef")},
+	}, nil).Once()
+	mockIAMClient.On("GetRole", mock.Anything, &iam.GetRoleInput{RoleName: aws.String("red-canary-resource-discovery-role")}).Return(nil, errors.New("access denied")).Once()
+
+	simulator := &Simulator{
+		CredentialsManager: mockCredentialsManager,
+		ClientFactory:      func(cfg aws.Config) interfaces.IAMClient { return mockIAMClient },
+		CallerAccountId:    "111111111111",
+		CallerArn:          "arn:aws:sts::111111111111:assumed-role/AWSReservedSSO_Admin_0123456789abcdef/session",
+		Partition:          "aws",
+		Region:             "us-east-1",
+		Actions:            []string{"ec2:DescribeInstances", "ecs:ListClusters", "eks:ListClusters", "tag:GetResources"},
+	}
+
+	denied, err := simulator.Simulate(context.TODO(), "222222222222")
+	assert.NoError(t, err)
+	assert.Equal(t, []interfaces.DeniedAction{
+		{AccountId: "222222222222", Action: "ecs:ListClusters", DeniedBy: interfaces.DeniedBySCP, Decision: "explicitDeny"},
+		{AccountId: "222222222222", Action: "eks:ListClusters", DeniedBy: interfaces.DeniedByPermissionBoundary, Decision: "implicitDeny"},
+		{AccountId: "222222222222", Action: "tag:GetResources", DeniedBy: interfaces.DeniedByIdentityPolicy, Decision: "implicitDeny"},
+	}, denied)
+
+	_, err = simulator.Simulate(context.TODO(), "111111111111")
+	assert.ErrorContains(t, err, "arn:aws:iam::111111111111:role/aws-reserved/sso.amazonaws.com/AWSReservedSSO_Admin_0123456789abcdef")
+
+	_, err = simulator.Simulate(context.TODO(), "333333333333")
+	assert.Error(t, err)
+	mockIAMClient.AssertExpectations(t)
+}
+
+func TestPrincipalArn(t *testing.T) {
+	assert.Equal(t, "arn:aws-us-gov:iam::123456789012:role/Admin", principalArn("arn:aws-us-gov:sts::123456789012:assumed-role/Admin/session"))
+	assert.Equal(t, "arn:aws:iam::123456789012:user/scanner", principalArn("arn:aws:iam::123456789012:user/scanner"))
+}
@@ -0,0 +1,247 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/logger"
+	"aws-resource-discovery/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	config_types "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+)
+
+// aggregatorResourceTypes are the resource types whose AWS Config count matches
+// the count produced by ResourceScanner.
+var aggregatorResourceTypes = []string{
+	"AWS::DynamoDB::Table",
+	"AWS::EC2::Instance",
+	"AWS::EC2::Volume",
+	"AWS::EFS::FileSystem",
+	"AWS::Lambda::Function",
+	"AWS::RDS::DBInstance",
+	"AWS::S3::Bucket",
+}
+
+// AggregatorScanner counts resources of every account and region with a single
+// advanced query against an AWS Config aggregator.
+type AggregatorScanner struct {
+	Client         interfaces.ConfigServiceClient
+	AggregatorName string
+	OrgAccounts    []types.Account
+	Regions        []string
+	Logger         interfaces.Logger
+	// OrgClient resolves the organizational unit of every account. Without it
+	// the organizational unit of every account is unknown.
+	OrgClient interfaces.OrganizationsClient
+	// Uncovered lists, per account, the regions the aggregator has no data for.
+	Uncovered map[string][]string
+	// Scanned lists the accounts the aggregator has data for in at least one
+	// region once Call returns.
+	Scanned []string
+	// Totals holds the totals of the selected accounts and regions once Call returns.
+	Totals interfaces.ResourceTotals
+	// AccountTotals and OUTotals hold the totals of every account and of every
+	// organizational unit path once Call returns.
+	AccountTotals map[string]*interfaces.ResourceTotals
+	OUTotals      map[string]*interfaces.ResourceTotals
+}
+
+// aggregateCount is a row of the advanced query result.
+type aggregateCount struct {
+	AccountId    string `json:"accountId"`
+	AwsRegion    string `json:"awsRegion"`
+	ResourceType string `json:"resourceType"`
+	Count        int    `json:"COUNT(*)"`
+}
+
+func (s *AggregatorScanner) Call() error {
+	ctx := context.TODO()
+	covered, err := s.coveredSources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to describe sources of aggregator %s: %w", s.AggregatorName, err)
+	}
+
+	counts, err := s.selectCounts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query aggregator %s: %w", s.AggregatorName, err)
+	}
+
+	totals := interfaces.ResourceTotals{}
+	accounts := s.accountIds()
+	regions := toSet(s.Regions)
+	ouPaths := organizationalUnitPaths(s.OrgClient, s.OrgAccounts, s.Logger)
+	s.AccountTotals = map[string]*interfaces.ResourceTotals{}
+	s.OUTotals = map[string]*interfaces.ResourceTotals{}
+	for _, account := range s.OrgAccounts {
+		accountId := aws.ToString(account.Id)
+		s.AccountTotals[accountId] = &interfaces.ResourceTotals{}
+		if s.OUTotals[ouPaths[accountId]] == nil {
+			s.OUTotals[ouPaths[accountId]] = &interfaces.ResourceTotals{}
+		}
+	}
+	for _, count := range counts {
+		if !accounts[count.AccountId] || !regions[count.AwsRegion] {
+			continue
+		}
+		logger.WithColumn(s.Logger, ouColumn, ouPaths[count.AccountId]).Log(reportRecord{
+			kind:         kindCount,
+			account:      count.AccountId,
+			region:       count.AwsRegion,
+			resourceType: count.ResourceType,
+			count:        strconv.Itoa(count.Count),
+			source:       config.SourceConfigAggregator,
+		}.columns())
+		addToTotals(&totals, count.ResourceType, count.Count)
+		addToTotals(s.AccountTotals[count.AccountId], count.ResourceType, count.Count)
+		addToTotals(s.OUTotals[ouPaths[count.AccountId]], count.ResourceType, count.Count)
+	}
+
+	s.Uncovered = s.uncoveredSources(covered)
+	for _, accountId := range sortedKeys(s.Uncovered) {
+		for _, region := range s.Uncovered[accountId] {
+			s.Logger.Logf("Account %s in region %s is not covered by aggregator %s", accountId, region, s.AggregatorName)
+		}
+	}
+
+	s.Scanned = nil
+	for _, account := range s.OrgAccounts {
+		if len(s.Uncovered[aws.ToString(account.Id)]) < len(s.Regions) {
+			s.Scanned = append(s.Scanned, aws.ToString(account.Id))
+		}
+	}
+
+	s.Totals = totals
+	s.printSummary(totals)
+	return nil
+}
+
+// selectCounts counts the supported resource types grouped by account, region and type.
+func (s *AggregatorScanner) selectCounts(ctx context.Context) ([]aggregateCount, error) {
+	input := &configservice.SelectAggregateResourceConfigInput{
+		ConfigurationAggregatorName: aws.String(s.AggregatorName),
+		Expression:                  aws.String(aggregatorQuery()),
+		MaxResults:                  100,
+	}
+
+	counts := []aggregateCount{}
+	for {
+		output, err := s.Client.SelectAggregateResourceConfig(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range output.Results {
+			var count aggregateCount
+			if err := json.Unmarshal([]byte(row), &count); err != nil {
+				return nil, fmt.Errorf("failed to parse query result %q: %w", row, err)
+			}
+			counts = append(counts, count)
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return counts, nil
+}
+
+// coveredSources returns the account and region pairs, keyed as "account/region",
+// the aggregator last collected successfully.
+func (s *AggregatorScanner) coveredSources(ctx context.Context) (map[string]bool, error) {
+	input := &configservice.DescribeConfigurationAggregatorSourcesStatusInput{
+		ConfigurationAggregatorName: aws.String(s.AggregatorName),
+	}
+
+	covered := map[string]bool{}
+	for {
+		output, err := s.Client.DescribeConfigurationAggregatorSourcesStatus(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range output.AggregatedSourceStatusList {
+			if status.LastUpdateStatus == config_types.AggregatedSourceStatusTypeFailed {
+				continue
+			}
+			covered[aws.ToString(status.SourceId)+"/"+aws.ToString(status.AwsRegion)] = true
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return covered, nil
+}
+
+// uncoveredSources returns the scanned regions of each account that are not covered.
+func (s *AggregatorScanner) uncoveredSources(covered map[string]bool) map[string][]string {
+	uncovered := map[string][]string{}
+	for _, account := range s.OrgAccounts {
+		for _, region := range s.Regions {
+			if !covered[aws.ToString(account.Id)+"/"+region] {
+				uncovered[aws.ToString(account.Id)] = append(uncovered[aws.ToString(account.Id)], region)
+			}
+		}
+	}
+	return uncovered
+}
+
+func (s *AggregatorScanner) accountIds() map[string]bool {
+	accounts := map[string]bool{}
+	for _, account := range s.OrgAccounts {
+		accounts[aws.ToString(account.Id)] = true
+	}
+	return accounts
+}
+
+func (s *AggregatorScanner) printSummary(totals interfaces.ResourceTotals) {
+	if len(s.OrgAccounts) == 1 {
+		fmt.Println("\nScanned 1 AWS account with AWS Config.")
+	} else {
+		fmt.Printf("\nScanned %d AWS accounts with AWS Config.\n", len(s.OrgAccounts))
+	}
+	fmt.Printf("\n")
+	utils.PrintTotals(totals)
+
+	fmt.Println("\nAWS Config does not report container hosts, serverless containers or container registry images; scan the accounts the normal way to count them.")
+	if len(s.Uncovered) == 0 {
+		return
+	}
+	fmt.Printf("\nThe following accounts and regions are not covered by aggregator %s and must be scanned the normal way:\n", s.AggregatorName)
+	for _, accountId := range sortedKeys(s.Uncovered) {
+		fmt.Printf("  %s: %s\n", accountId, strings.Join(s.Uncovered[accountId], ", "))
+	}
+}
+
+// aggregatorQuery returns the advanced query counting the supported resource types.
+func aggregatorQuery() string {
+	quoted := make([]string, len(aggregatorResourceTypes))
+	for i, resourceType := range aggregatorResourceTypes {
+		quoted[i] = "'" + resourceType + "'"
+	}
+	return fmt.Sprintf("SELECT accountId, awsRegion, resourceType, COUNT(*) WHERE resourceType IN (%s) GROUP BY accountId, awsRegion, resourceType", strings.Join(quoted, ", "))
+}
+
+func toSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
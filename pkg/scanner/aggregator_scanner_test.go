@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"errors"
+	"testing"
+
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	config_types "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAggregatorScanner_Call(t *testing.T) {
+	mockClient := new(mocks.MockConfigServiceClient)
+	mockLogger := new(mocks.MockLogger)
+
+	mockClient.On("DescribeConfigurationAggregatorSourcesStatus", mock.Anything, mock.Anything).Return(&configservice.DescribeConfigurationAggregatorSourcesStatusOutput{
+		AggregatedSourceStatusList: []config_types.AggregatedSourceStatus{
+			{SourceId: aws.String("111111111111"), AwsRegion: aws.String("us-east-1"), LastUpdateStatus: config_types.AggregatedSourceStatusTypeSucceeded},
+			{SourceId: aws.String("111111111111"), AwsRegion: aws.String("us-west-2"), LastUpdateStatus: config_types.AggregatedSourceStatusTypeFailed},
+			{SourceId: aws.String("222222222222"), AwsRegion: aws.String("us-east-1"), LastUpdateStatus: config_types.AggregatedSourceStatusTypeOutdated},
+		},
+	}, nil).Once()
+	mockClient.On("SelectAggregateResourceConfig", mock.Anything, mock.MatchedBy(func(input *configservice.SelectAggregateResourceConfigInput) bool {
+		return aws.ToString(input.ConfigurationAggregatorName) == "org-aggregator" && input.NextToken == nil
+	})).Return(&configservice.SelectAggregateResourceConfigOutput{
+		Results: []string{
+			`{"accountId":"111111111111","awsRegion":"us-east-1","resourceType":"AWS::EC2::Instance","COUNT(*)":3}`,
+			`{"accountId":"333333333333","awsRegion":"us-east-1","resourceType":"AWS::EC2::Instance","COUNT(*)":9}`,
+		},
+		NextToken: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("SelectAggregateResourceConfig", mock.Anything, mock.MatchedBy(func(input *configservice.SelectAggregateResourceConfigInput) bool {
+		return aws.ToString(input.NextToken) == "next"
+	})).Return(&configservice.SelectAggregateResourceConfigOutput{
+		Results: []string{
+			`{"accountId":"222222222222","awsRegion":"us-east-1","resourceType":"AWS::S3::Bucket","COUNT(*)":2}`,
+		},
+	}, nil).Once()
+	mockLogger.On("Log", mock.Anything).Return(nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	scanner := &AggregatorScanner{
+		Client:         mockClient,
+		AggregatorName: "org-aggregator",
+		OrgAccounts:    []types.Account{{Id: aws.String("111111111111")}, {Id: aws.String("222222222222")}},
+		Regions:        []string{"us-east-1", "us-west-2"},
+		Logger:         mockLogger,
+	}
+
+	output := captureOutput(func() {
+		assert.NoError(t, scanner.Call())
+	})
+
+	mockLogger.AssertCalled(t, "Log", []string{"count", "111111111111", "unknown", "us-east-1", "AWS::EC2::Instance", "3", "config-aggregator", "", "", "", ""})
+	mockLogger.AssertCalled(t, "Log", []string{"count", "222222222222", "unknown", "us-east-1", "AWS::S3::Bucket", "2", "config-aggregator", "", "", "", ""})
+	mockLogger.AssertNumberOfCalls(t, "Log", 2)
+	assert.Equal(t, map[string][]string{
+		"111111111111": {"us-west-2"},
+		"222222222222": {"us-west-2"},
+	}, scanner.Uncovered)
+	assert.Equal(t, []string{"111111111111", "222222222222"}, scanner.Scanned)
+	assert.Equal(t, 3, scanner.Totals.VirtualMachines)
+	assert.Equal(t, map[string]*interfaces.ResourceTotals{
+		"111111111111": {VirtualMachines: 3},
+		"222222222222": {Buckets: 2},
+	}, scanner.AccountTotals)
+	assert.Equal(t, map[string]*interfaces.ResourceTotals{"unknown": {VirtualMachines: 3, Buckets: 2}}, scanner.OUTotals)
+	assert.Contains(t, output, "Scanned 2 AWS accounts with AWS Config.")
+	assert.Contains(t, output, "111111111111: us-west-2")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestAggregatorScanner_CallWithError(t *testing.T) {
+	mockClient := new(mocks.MockConfigServiceClient)
+	expectedError := errors.New("test error")
+
+	mockClient.On("DescribeConfigurationAggregatorSourcesStatus", mock.Anything, mock.Anything).Return(&configservice.DescribeConfigurationAggregatorSourcesStatusOutput{}, nil).Once()
+	mockClient.On("SelectAggregateResourceConfig", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	scanner := &AggregatorScanner{Client: mockClient, AggregatorName: "org-aggregator"}
+
+	err := scanner.Call()
+	assert.ErrorIs(t, err, expectedError)
+	assert.Contains(t, err.Error(), "failed to query aggregator org-aggregator")
+}
+
+func TestAggregatorQuery(t *testing.T) {
+	query := aggregatorQuery()
+	assert.Contains(t, query, "SELECT accountId, awsRegion, resourceType, COUNT(*)")
+	assert.Contains(t, query, "'AWS::EC2::Instance'")
+	assert.Contains(t, query, "GROUP BY accountId, awsRegion, resourceType")
+}
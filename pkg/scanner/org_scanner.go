@@ -2,15 +2,25 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/logger"
 	"aws-resource-discovery/pkg/utils"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
 )
 
+// rootPath is the organizational unit path of the accounts directly under the root.
+const rootPath = "Root"
+
+// unknownPath is the organizational unit path of the accounts whose parents could not be listed.
+const unknownPath = "unknown"
+
 type OrgScanner struct {
 	CredentialsManager interfaces.CredentialsManager
 	OrgAccounts        []types.Account
@@ -18,7 +28,39 @@ type OrgScanner struct {
 	Regions            []string
 	STSClient          interfaces.STSClient
 	OrgClient          interfaces.OrganizationsClient
-	ScannerFactory     func(accountId, region string, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface
+	// ScannerFactory creates the scanner of an account in a region; global is
+	// set in the one region of the account where global resources are counted.
+	ScannerFactory func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface
+	// CallerAccountId is the account of the caller credentials. It is scanned
+	// with those credentials instead of assuming a role into it.
+	CallerAccountId string
+	// SourceCovers reports whether the count source covers every resource type
+	// counted in an account and region. No role is assumed to scan them.
+	SourceCovers func(accountId, region string, global bool) bool
+	// Totals holds the totals of every scanned account once Call returns.
+	Totals interfaces.ResourceTotals
+	// AccountTotals and OUTotals hold the totals of every account and of every
+	// organizational unit path once Call returns.
+	AccountTotals map[string]*interfaces.ResourceTotals
+	OUTotals      map[string]*interfaces.ResourceTotals
+	// Scanned lists the accounts scanned in at least one region once Call
+	// returns.
+	Scanned []string
+	// RegionsManager describes the regions enabled in every account. Without
+	// it every account is scanned in Regions.
+	RegionsManager interfaces.RegionsManager
+	// AllRegions scans every region enabled in an account instead of the
+	// ones of Regions that are enabled in it.
+	AllRegions bool
+	// ExcludedRegions are left out of the regions enabled in an account when
+	// scanning all regions.
+	ExcludedRegions []string
+	// Profile is the profile whose configuration the regions are described with.
+	Profile string
+	// Report records the status of the regions of every account.
+	Report *interfaces.ScanReport
+	// Retries is the retry configuration of the clients that describe the regions.
+	Retries *utils.Retries
 }
 
 type ResourceScannerInterface interface {
@@ -27,27 +69,232 @@ type ResourceScannerInterface interface {
 
 func (s *OrgScanner) Call() {
 	totals := interfaces.ResourceTotals{}
+	s.Scanned = nil
+	accountTotals := map[string]*interfaces.ResourceTotals{}
+	ouTotals := map[string]*interfaces.ResourceTotals{}
+	ouPaths := organizationalUnitPaths(s.OrgClient, s.OrgAccounts, s.Logger)
 	progress := newProgressReporter(len(s.OrgAccounts), len(s.Regions))
 
 	for _, account := range s.OrgAccounts {
-		for _, region := range s.Regions {
+		accountTotal := &interfaces.ResourceTotals{}
+		accountLogger := logger.WithColumn(s.Logger, ouColumn, ouPaths[*account.Id])
+		regions := s.accountRegions(&account)
+		progress.adjust(len(regions) - len(s.Regions))
+		// The global resources are counted in the first region of the account
+		// that can be scanned.
+		global := true
+		for _, region := range globalRegionFirst(regions) {
 			progress.report()
-			s.scanOne(&account, region, &totals)
+			if s.scanOne(&account, region, global, accountLogger, accountTotal) {
+				global = false
+			}
+		}
+		if !global {
+			s.Scanned = append(s.Scanned, *account.Id)
+		}
+
+		totals.Add(*accountTotal)
+		accountTotals[*account.Id] = accountTotal
+		path := ouPaths[*account.Id]
+		if ouTotals[path] == nil {
+			ouTotals[path] = &interfaces.ResourceTotals{}
 		}
+		ouTotals[path].Add(*accountTotal)
 	}
 
+	s.Totals = totals
+	s.AccountTotals = accountTotals
+	s.OUTotals = ouTotals
 	s.printSummary(totals)
+	if len(s.OrgAccounts) > 1 {
+		s.printSubtotals(accountTotals, ouTotals)
+	}
 }
 
-func (s *OrgScanner) scanOne(account *types.Account, region string, totals *interfaces.ResourceTotals) {
-	orgCreds, err := s.CredentialsManager.CredentialsFor(context.TODO(), *account.Id, region)
-	if err != nil {
-		s.Logger.Logf("Failed to get credentials for account %s in region %s: %v", *account.Id, region, err)
-		return
+// scanOne scans an account in a region, counting its global resources too when
+// global is set. It reports whether the region was scanned, that is whether the
+// credentials were obtained and the region is not blocked in the account.
+func (s *OrgScanner) scanOne(account *types.Account, region string, global bool, logger interfaces.Logger, totals *interfaces.ResourceTotals) bool {
+	// Empty credentials make the resource scanner use the caller credentials.
+	orgCreds := aws.Credentials{}
+	if s.needsRole(*account.Id, region, global) {
+		var err error
+		orgCreds, err = s.CredentialsManager.CredentialsFor(context.TODO(), *account.Id, region)
+		if err != nil {
+			s.Logger.Logf("Failed to get credentials for account %s in region %s: %v", *account.Id, region, err)
+			return false
+		}
 	}
 
-	resourceScanner := s.ScannerFactory(*account.Id, region, orgCreds, s.Logger, totals)
+	resourceScanner := s.ScannerFactory(*account.Id, region, global, orgCreds, logger, totals)
 	resourceScanner.Call()
+	return s.Report == nil || s.Report.RegionStatuses[*account.Id][region] != interfaces.RegionBlocked
+}
+
+// needsRole reports whether a role must be assumed into an account to scan a
+// region: the account is not the caller one and the count source does not
+// cover every resource type of the region.
+func (s *OrgScanner) needsRole(accountId, region string, global bool) bool {
+	if accountId == s.CallerAccountId {
+		return false
+	}
+	return s.SourceCovers == nil || !s.SourceCovers(accountId, region, global)
+}
+
+// globalRegionFirst moves the global region of the partition to the front of
+// the regions, so that global resources are counted there when it is scanned
+// and in the next available region otherwise.
+func globalRegionFirst(regions []string) []string {
+	if len(regions) == 0 {
+		return regions
+	}
+	globalRegion := utils.GlobalRegion(utils.PartitionForRegion(regions[0]))
+	ordered := make([]string, 0, len(regions))
+	for _, region := range regions {
+		if region == globalRegion {
+			ordered = append(ordered, region)
+		}
+	}
+	for _, region := range regions {
+		if region != globalRegion {
+			ordered = append(ordered, region)
+		}
+	}
+	return ordered
+}
+
+// accountRegions returns the regions to scan in an account: the regions
+// enabled in it when scanning all regions, and otherwise the ones of Regions
+// that are enabled in it. The status of the regions is recorded in the report.
+// Regions is returned when the regions of the account cannot be described, and
+// without describing them when the count source covers the account in all of
+// them.
+func (s *OrgScanner) accountRegions(account *types.Account) []string {
+	if s.RegionsManager == nil || len(s.Regions) == 0 || s.sourceCoversRegions(*account.Id) {
+		return s.Regions
+	}
+
+	ctx := context.TODO()
+	// The regions are described in a region that is scanned, since service
+	// control policies often deny the global region.
+	region := s.Regions[0]
+	creds := aws.Credentials{}
+	if *account.Id != s.CallerAccountId {
+		var err error
+		creds, err = s.CredentialsManager.CredentialsFor(ctx, *account.Id, region)
+		if err != nil {
+			s.Logger.Logf("Failed to get credentials to describe the regions of account %s: %v", *account.Id, err)
+			return s.Regions
+		}
+	}
+	cfg, err := newSession(ctx, s.Profile, region, creds, s.Retries)
+	if err != nil {
+		s.Logger.Logf("Failed to initialize AWS session for account %s: %v", *account.Id, err)
+		return s.Regions
+	}
+	statuses, err := s.RegionsManager.AccountRegions(ctx, cfg)
+	if err != nil {
+		s.Logger.Logf("Failed to describe the regions of account %s, scanning the selected regions: %v", *account.Id, err)
+		return s.Regions
+	}
+
+	considered := s.Regions
+	if s.AllRegions {
+		considered = make([]string, 0, len(statuses))
+		for region := range statuses {
+			considered = append(considered, region)
+		}
+		considered = excludeRegions(considered, s.ExcludedRegions)
+		sort.Strings(considered)
+	}
+	regions := []string{}
+	recorded := map[string]string{}
+	for _, region := range considered {
+		status, ok := statuses[region]
+		if !ok {
+			status = interfaces.RegionDisabled
+		}
+		recorded[region] = status
+		if status == interfaces.RegionEnabled {
+			regions = append(regions, region)
+		} else {
+			s.Logger.Logf("Skipping region %s disabled in account %s", region, *account.Id)
+		}
+	}
+	if s.Report != nil {
+		s.Report.SetRegionStatuses(*account.Id, recorded)
+	}
+	return regions
+}
+
+// sourceCoversRegions reports whether the count source covers every resource
+// type of an account in every region of Regions, counting its global resources
+// in the first one scanned.
+func (s *OrgScanner) sourceCoversRegions(accountId string) bool {
+	if s.SourceCovers == nil || s.AllRegions {
+		return false
+	}
+	for i, region := range globalRegionFirst(s.Regions) {
+		if !s.SourceCovers(accountId, region, i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// organizationalUnitPaths returns the organizational unit path of every account,
+// such as "Root/Workloads/Prod". Accounts whose parents cannot be listed, as
+// happens outside an organization or without permission, are marked as
+// unknown, and so are all accounts without an organizations client.
+func organizationalUnitPaths(client interfaces.OrganizationsClient, accounts []types.Account, logger interfaces.Logger) map[string]string {
+	paths := map[string]string{}
+	cache := map[string]string{}
+	for _, account := range accounts {
+		path := unknownPath
+		if client != nil {
+			var err error
+			path, err = organizationalUnitPath(client, *account.Id, cache)
+			if err != nil {
+				logger.Logf("Failed to resolve the organizational unit of account %s: %v", *account.Id, err)
+				path = unknownPath
+			}
+		}
+		paths[*account.Id] = path
+	}
+	return paths
+}
+
+// organizationalUnitPath walks the parents of an account or organizational unit
+// up to the root. The paths of the organizational units are cached by id.
+func organizationalUnitPath(client interfaces.OrganizationsClient, childId string, cache map[string]string) (string, error) {
+	output, err := client.ListParents(context.TODO(), &organizations.ListParentsInput{ChildId: aws.String(childId)})
+	if err != nil {
+		return "", err
+	}
+	if len(output.Parents) == 0 {
+		return "", errors.New("no parent found for " + childId)
+	}
+	parent := output.Parents[0]
+	parentId := aws.ToString(parent.Id)
+	if path, ok := cache[parentId]; ok {
+		return path, nil
+	}
+	if parent.Type == types.ParentTypeRoot {
+		cache[parentId] = rootPath
+		return rootPath, nil
+	}
+
+	ou, err := client.DescribeOrganizationalUnit(context.TODO(), &organizations.DescribeOrganizationalUnitInput{OrganizationalUnitId: parent.Id})
+	if err != nil {
+		return "", err
+	}
+	parentPath, err := organizationalUnitPath(client, parentId, cache)
+	if err != nil {
+		return "", err
+	}
+	path := parentPath + "/" + aws.ToString(ou.OrganizationalUnit.Name)
+	cache[parentId] = path
+	return path, nil
 }
 
 func newProgressReporter(accountsCount, regionsCount int) *progressReporter {
@@ -67,11 +314,30 @@ func (s *OrgScanner) printSummary(totals interfaces.ResourceTotals) {
 	utils.PrintTotals(totals)
 }
 
+// printSubtotals prints the totals of every organizational unit, unless none
+// was resolved, and of every account.
+func (s *OrgScanner) printSubtotals(accountTotals, ouTotals map[string]*interfaces.ResourceTotals) {
+	if _, unresolved := ouTotals[unknownPath]; !unresolved || len(ouTotals) > 1 {
+		utils.PrintSubtotals("OrganizationalUnit", sortedKeys(ouTotals), ouTotals)
+	}
+	accountIds := make([]string, 0, len(s.OrgAccounts))
+	for _, account := range s.OrgAccounts {
+		accountIds = append(accountIds, *account.Id)
+	}
+	utils.PrintSubtotals("Account", accountIds, accountTotals)
+}
+
 type progressReporter struct {
 	count int
 	total int
 }
 
+// adjust changes the total by the difference between the regions scanned in
+// an account and the expected ones.
+func (p *progressReporter) adjust(delta int) {
+	p.total += delta
+}
+
 func (p *progressReporter) report() {
 	fmt.Printf("Red Canary - AWS Resource Discovery Scan Progress: %d / %d ...\r", p.count, p.total)
 	p.count++
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"os"
 	"testing"
@@ -10,7 +11,9 @@ import (
 	"aws-resource-discovery/pkg/mocks"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/rodaine/table"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -35,6 +38,9 @@ func TestOrgScanner_Call(t *testing.T) {
 	mockCredentialsManager.On("CredentialsFor", mock.Anything, "account2", "us-west-2").Return(mockCredentials, nil)
 
 	mockResourceScanner.On("Call").Return(nil)
+	mockOrgClient.On("ListParents", mock.Anything, mock.Anything).Return(&organizations.ListParentsOutput{
+		Parents: []types.Parent{{Id: aws.String("r-root"), Type: types.ParentTypeRoot}},
+	}, nil)
 
 	scanner := &OrgScanner{
 		CredentialsManager: mockCredentialsManager,
@@ -43,7 +49,7 @@ func TestOrgScanner_Call(t *testing.T) {
 		Regions:            regions,
 		STSClient:          mockSTSClient,
 		OrgClient:          mockOrgClient,
-		ScannerFactory: func(accountId, region string, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
 			return mockResourceScanner
 		},
 	}
@@ -55,6 +61,323 @@ func TestOrgScanner_Call(t *testing.T) {
 	mockLogger.AssertNotCalled(t, "Logf")
 }
 
+func TestOrgScanner_CallWithCallerAccount(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockResourceScanner := new(mocks.MockResourceScanner)
+	assumed := aws.Credentials{AccessKeyID: "assumed"}
+
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "account2", "us-east-1").Return(assumed, nil)
+	mockResourceScanner.On("Call").Return(nil)
+
+	scannedWith := map[string]aws.Credentials{}
+	scanner := &OrgScanner{
+		CredentialsManager: mockCredentialsManager,
+		OrgAccounts:        []types.Account{{Id: aws.String("account1")}, {Id: aws.String("account2")}},
+		Logger:             new(mocks.MockLogger),
+		Regions:            []string{"us-east-1"},
+		CallerAccountId:    "account1",
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+			scannedWith[accountId] = credentials
+			return mockResourceScanner
+		},
+	}
+
+	captureOutput(scanner.Call)
+
+	// The caller account is scanned with empty credentials, which stand for the caller credentials
+	assert.Equal(t, map[string]aws.Credentials{"account1": {}, "account2": assumed}, scannedWith)
+	mockCredentialsManager.AssertNotCalled(t, "CredentialsFor", mock.Anything, "account1", mock.Anything)
+}
+
+func TestOrgScanner_CallWithSourceCoverage(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockResourceScanner := new(mocks.MockResourceScanner)
+	assumed := aws.Credentials{AccessKeyID: "assumed"}
+
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "account1", "us-west-2").Return(assumed, nil)
+	mockResourceScanner.On("Call").Return(nil)
+
+	scannedWith := map[string]aws.Credentials{}
+	scanner := &OrgScanner{
+		CredentialsManager: mockCredentialsManager,
+		OrgAccounts:        []types.Account{{Id: aws.String("account1")}},
+		Logger:             new(mocks.MockLogger),
+		Regions:            []string{"us-east-1", "us-west-2"},
+		SourceCovers: func(accountId, region string, global bool) bool {
+			return region == "us-east-1"
+		},
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+			scannedWith[region] = credentials
+			return mockResourceScanner
+		},
+	}
+
+	captureOutput(scanner.Call)
+
+	// The role is only assumed in the region the count source does not cover
+	assert.Equal(t, map[string]aws.Credentials{"us-east-1": {}, "us-west-2": assumed}, scannedWith)
+	mockCredentialsManager.AssertNotCalled(t, "CredentialsFor", mock.Anything, "account1", "us-east-1")
+}
+
+func TestOrgScanner_accountRegionsCoveredBySource(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockRegionsManager := new(mocks.MockRegionsManager)
+	scanner := &OrgScanner{
+		CredentialsManager: mockCredentialsManager,
+		Regions:            []string{"us-east-1", "us-west-2"},
+		RegionsManager:     mockRegionsManager,
+		SourceCovers: func(accountId, region string, global bool) bool {
+			return true
+		},
+	}
+
+	// The regions are not described when no role is assumed to scan them
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, scanner.accountRegions(&types.Account{Id: aws.String("account1")}))
+	mockCredentialsManager.AssertNotCalled(t, "CredentialsFor", mock.Anything, mock.Anything, mock.Anything)
+	mockRegionsManager.AssertNotCalled(t, "AccountRegions", mock.Anything, mock.Anything)
+}
+
+func TestOrgScanner_CallWithOrganizationalUnits(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockLogger := new(mocks.MockLogger)
+	mockOrgClient := new(mocks.MockOrganizationsClient)
+
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, mock.Anything, mock.Anything).Return(aws.Credentials{}, nil)
+	mockOrgClient.On("ListParents", mock.Anything, &organizations.ListParentsInput{ChildId: aws.String("account1")}).Return(&organizations.ListParentsOutput{
+		Parents: []types.Parent{{Id: aws.String("ou-prod"), Type: types.ParentTypeOrganizationalUnit}},
+	}, nil)
+	mockOrgClient.On("ListParents", mock.Anything, &organizations.ListParentsInput{ChildId: aws.String("account2")}).Return(&organizations.ListParentsOutput{
+		Parents: []types.Parent{{Id: aws.String("ou-prod"), Type: types.ParentTypeOrganizationalUnit}},
+	}, nil)
+	mockOrgClient.On("ListParents", mock.Anything, &organizations.ListParentsInput{ChildId: aws.String("account3")}).Return(nil, errors.New("test error"))
+	mockOrgClient.On("ListParents", mock.Anything, &organizations.ListParentsInput{ChildId: aws.String("ou-prod")}).Return(&organizations.ListParentsOutput{
+		Parents: []types.Parent{{Id: aws.String("r-root"), Type: types.ParentTypeRoot}},
+	}, nil).Once()
+	mockOrgClient.On("DescribeOrganizationalUnit", mock.Anything, mock.Anything).Return(&organizations.DescribeOrganizationalUnitOutput{
+		OrganizationalUnit: &types.OrganizationalUnit{Id: aws.String("ou-prod"), Name: aws.String("Prod")},
+	}, nil).Once()
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Log", []string{"count", "account1", "Root/Prod", "us-east-1", "AWS::EC2::Instance", "2"}).Return(nil)
+	mockLogger.On("Log", []string{"count", "account2", "Root/Prod", "us-east-1", "AWS::EC2::Instance", "2"}).Return(nil)
+	mockLogger.On("Log", []string{"count", "account3", "unknown", "us-east-1", "AWS::EC2::Instance", "2"}).Return(nil)
+
+	scanner := &OrgScanner{
+		CredentialsManager: mockCredentialsManager,
+		OrgAccounts: []types.Account{
+			{Id: aws.String("account1")},
+			{Id: aws.String("account2")},
+			{Id: aws.String("account3")},
+		},
+		Logger:    mockLogger,
+		Regions:   []string{"us-east-1"},
+		OrgClient: mockOrgClient,
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+			return &fixedScanner{call: func() {
+				logger.Log([]string{"count", accountId, region, "AWS::EC2::Instance", "2"})
+				totals.VirtualMachines += 2
+			}}
+		},
+	}
+
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	captureOutput(scanner.Call)
+	output := buf.String()
+
+	assert.Contains(t, output, "OrganizationalUnit")
+	assert.Contains(t, output, "Root/Prod")
+	assert.Contains(t, output, "unknown")
+	assert.Contains(t, output, "account3")
+	assert.Equal(t, 6, scanner.Totals.VirtualMachines)
+	assert.Equal(t, map[string]*interfaces.ResourceTotals{
+		"Root/Prod": {VirtualMachines: 4},
+		"unknown":   {VirtualMachines: 2},
+	}, scanner.OUTotals)
+	assert.Equal(t, 2, scanner.AccountTotals["account3"].VirtualMachines)
+	mockLogger.AssertExpectations(t)
+	mockOrgClient.AssertExpectations(t)
+}
+
+func TestOrgScanner_CallSkipsDisabledRegions(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockLogger := new(mocks.MockLogger)
+	mockRegionsManager := new(mocks.MockRegionsManager)
+	mockResourceScanner := new(mocks.MockResourceScanner)
+
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "account1", mock.Anything).Return(aws.Credentials{}, nil)
+	mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(map[string]string{
+		"us-east-1":  interfaces.RegionEnabled,
+		"me-south-1": interfaces.RegionDisabled,
+	}, nil)
+	mockResourceScanner.On("Call").Return(nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything).Return(nil)
+
+	report := &interfaces.ScanReport{}
+	scannedRegions := []string{}
+	scanner := &OrgScanner{
+		CredentialsManager: mockCredentialsManager,
+		OrgAccounts:        []types.Account{{Id: aws.String("account1")}},
+		Logger:             mockLogger,
+		Regions:            []string{"us-east-1", "me-south-1"},
+		RegionsManager:     mockRegionsManager,
+		Report:             report,
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+			scannedRegions = append(scannedRegions, region)
+			return mockResourceScanner
+		},
+	}
+
+	scanner.Call()
+
+	assert.Equal(t, []string{"us-east-1"}, scannedRegions)
+	assert.Equal(t, map[string]string{"us-east-1": interfaces.RegionEnabled, "me-south-1": interfaces.RegionDisabled}, report.RegionStatuses["account1"])
+	mockCredentialsManager.AssertNotCalled(t, "CredentialsFor", mock.Anything, "account1", "me-south-1")
+}
+
+func TestOrgScanner_CallSchedulesGlobalResources(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockLogger := new(mocks.MockLogger)
+	mockResourceScanner := new(mocks.MockResourceScanner)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "account1", mock.Anything).Return(aws.Credentials{}, nil)
+	mockResourceScanner.On("Call").Return(nil)
+
+	report := &interfaces.ScanReport{}
+	globalRegions := []string{}
+	scanner := &OrgScanner{
+		CredentialsManager: mockCredentialsManager,
+		OrgAccounts:        []types.Account{{Id: aws.String("account1")}},
+		Logger:             mockLogger,
+		Regions:            []string{"eu-west-1", "us-west-2", "us-east-1"},
+		Report:             report,
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+			if global {
+				globalRegions = append(globalRegions, region)
+			}
+			// us-east-1 is blocked by a service control policy
+			if region == "us-east-1" {
+				report.SetRegionStatus(accountId, region, interfaces.RegionBlocked)
+			}
+			return mockResourceScanner
+		},
+	}
+
+	scanner.Call()
+
+	assert.Equal(t, []string{"us-east-1", "eu-west-1"}, globalRegions)
+	assert.Equal(t, []string{"account1"}, scanner.Scanned)
+	mockResourceScanner.AssertNumberOfCalls(t, "Call", 3)
+}
+
+func TestOrgScanner_CallWithoutCredentials(t *testing.T) {
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockLogger := new(mocks.MockLogger)
+	mockResourceScanner := new(mocks.MockResourceScanner)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "account1", mock.Anything).Return(aws.Credentials{}, nil)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "account2", mock.Anything).Return(aws.Credentials{}, errors.New("access denied"))
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockResourceScanner.On("Call").Return(nil)
+
+	scanner := &OrgScanner{
+		CredentialsManager: mockCredentialsManager,
+		OrgAccounts:        []types.Account{{Id: aws.String("account1")}, {Id: aws.String("account2")}},
+		Logger:             mockLogger,
+		Regions:            []string{"us-east-1"},
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+			return mockResourceScanner
+		},
+	}
+
+	captureOutput(scanner.Call)
+
+	// Accounts the role could not be assumed into are left to later targets
+	assert.Equal(t, []string{"account1"}, scanner.Scanned)
+}
+
+func TestGlobalRegionFirst(t *testing.T) {
+	assert.Equal(t, []string{"us-east-1", "eu-west-1", "us-west-2"}, globalRegionFirst([]string{"eu-west-1", "us-east-1", "us-west-2"}))
+	assert.Equal(t, []string{"us-gov-west-1", "us-gov-east-1"}, globalRegionFirst([]string{"us-gov-east-1", "us-gov-west-1"}))
+	assert.Equal(t, []string{"eu-west-1"}, globalRegionFirst([]string{"eu-west-1"}))
+	assert.Empty(t, globalRegionFirst(nil))
+}
+
+func TestOrgScanner_accountRegions(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockRegionsManager := new(mocks.MockRegionsManager)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything).Return(nil)
+	account := &types.Account{Id: aws.String("account1")}
+
+	t.Run("All regions enabled in the account", func(t *testing.T) {
+		mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(map[string]string{
+			"us-east-1":  interfaces.RegionEnabled,
+			"af-south-1": interfaces.RegionEnabled,
+			"me-south-1": interfaces.RegionDisabled,
+		}, nil).Once()
+		scanner := &OrgScanner{Logger: mockLogger, Regions: []string{"us-east-1"}, RegionsManager: mockRegionsManager, AllRegions: true, CallerAccountId: "account1"}
+
+		assert.Equal(t, []string{"af-south-1", "us-east-1"}, scanner.accountRegions(account))
+	})
+
+	t.Run("Regions described in the first scanned region", func(t *testing.T) {
+		inRegion := mock.MatchedBy(func(cfg aws.Config) bool { return cfg.Region == "eu-west-1" })
+		mockRegionsManager.On("AccountRegions", mock.Anything, inRegion).Return(map[string]string{
+			"eu-west-1": interfaces.RegionEnabled,
+			"us-east-1": interfaces.RegionEnabled,
+		}, nil).Once()
+		scanner := &OrgScanner{Logger: mockLogger, Regions: []string{"eu-west-1", "us-east-1"}, RegionsManager: mockRegionsManager, CallerAccountId: "account1"}
+
+		assert.Equal(t, []string{"eu-west-1", "us-east-1"}, scanner.accountRegions(account))
+	})
+
+	t.Run("Selected regions when the regions cannot be described", func(t *testing.T) {
+		mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(nil, errors.New("access denied")).Once()
+		scanner := &OrgScanner{Logger: mockLogger, Regions: []string{"us-east-1", "eu-west-1"}, RegionsManager: mockRegionsManager, CallerAccountId: "account1"}
+
+		assert.Equal(t, []string{"us-east-1", "eu-west-1"}, scanner.accountRegions(account))
+	})
+}
+
+func TestOrgScanner_organizationalUnitPathsWithoutOrganization(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockOrgClient := new(mocks.MockOrganizationsClient)
+
+	mockOrgClient.On("ListParents", mock.Anything, mock.Anything).Return(nil, errors.New("AWSOrganizationsNotInUseException")).Twice()
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	accounts := []types.Account{{Id: aws.String("account1")}, {Id: aws.String("account2")}}
+
+	assert.Equal(t, map[string]string{"account1": "unknown", "account2": "unknown"}, organizationalUnitPaths(mockOrgClient, accounts, mockLogger))
+	assert.Equal(t, map[string]string{"account1": "unknown", "account2": "unknown"}, organizationalUnitPaths(nil, accounts, mockLogger))
+	mockOrgClient.AssertExpectations(t)
+	mockLogger.AssertNumberOfCalls(t, "Logf", 2)
+}
+
+func TestOrganizationalUnitPaths_FirstAccountFails(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockOrgClient := new(mocks.MockOrganizationsClient)
+
+	mockOrgClient.On("ListParents", mock.Anything, &organizations.ListParentsInput{ChildId: aws.String("account1")}).Return(nil, errors.New("throttled")).Once()
+	mockOrgClient.On("ListParents", mock.Anything, &organizations.ListParentsInput{ChildId: aws.String("account2")}).Return(&organizations.ListParentsOutput{
+		Parents: []types.Parent{{Id: aws.String("r-root"), Type: types.ParentTypeRoot}},
+	}, nil).Once()
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	accounts := []types.Account{{Id: aws.String("account1")}, {Id: aws.String("account2")}}
+
+	assert.Equal(t, map[string]string{"account1": "unknown", "account2": "Root"}, organizationalUnitPaths(mockOrgClient, accounts, mockLogger))
+	mockOrgClient.AssertExpectations(t)
+}
+
+// fixedScanner is a resource scanner that runs a fixed function.
+type fixedScanner struct {
+	call func()
+}
+
+func (s *fixedScanner) Call() {
+	s.call()
+}
+
 func TestProgressReporter(t *testing.T) {
 	progress := newProgressReporter(2, 2)
 
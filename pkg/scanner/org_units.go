@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+)
+
+// orgUnitAccounts resolves organizational units, given by ID or by path such as
+// "Root/Workloads/Sandbox", to the accounts they contain, including the accounts
+// of nested organizational units.
+type orgUnitAccounts struct {
+	Client interfaces.OrganizationsClient
+}
+
+// AccountIds returns the IDs of the accounts in any of the organizational units.
+func (o *orgUnitAccounts) AccountIds(ctx context.Context, units []string) (map[string]bool, error) {
+	ids := map[string]bool{}
+	for _, unit := range units {
+		parentId, err := o.resolve(ctx, unit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve organizational unit %s: %w", unit, err)
+		}
+		if err := o.collect(ctx, parentId, ids); err != nil {
+			return nil, fmt.Errorf("failed to list the accounts of organizational unit %s: %w", unit, err)
+		}
+	}
+	return ids, nil
+}
+
+// resolve returns the ID of an organizational unit given by ID or by path.
+func (o *orgUnitAccounts) resolve(ctx context.Context, unit string) (string, error) {
+	if strings.HasPrefix(unit, "ou-") || strings.HasPrefix(unit, "r-") {
+		return unit, nil
+	}
+
+	roots, err := o.Client.ListRoots(ctx, &organizations.ListRootsInput{})
+	if err != nil {
+		return "", err
+	}
+	if len(roots.Roots) == 0 {
+		return "", fmt.Errorf("no organization root found")
+	}
+	parentId := aws.ToString(roots.Roots[0].Id)
+	if unit == rootPath {
+		return parentId, nil
+	}
+
+	for _, name := range strings.Split(strings.TrimPrefix(unit, rootPath+"/"), "/") {
+		children, err := o.children(ctx, parentId)
+		if err != nil {
+			return "", err
+		}
+		found := false
+		for _, child := range children {
+			if aws.ToString(child.Name) == name {
+				parentId = aws.ToString(child.Id)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("no organizational unit named %s", name)
+		}
+	}
+	return parentId, nil
+}
+
+// collect adds the accounts under a parent, recursively, to ids.
+func (o *orgUnitAccounts) collect(ctx context.Context, parentId string, ids map[string]bool) error {
+	paginator := organizations.NewListAccountsForParentPaginator(o.Client, &organizations.ListAccountsForParentInput{
+		ParentId: aws.String(parentId),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, account := range output.Accounts {
+			ids[aws.ToString(account.Id)] = true
+		}
+	}
+
+	children, err := o.children(ctx, parentId)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := o.collect(ctx, aws.ToString(child.Id), ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// children returns the organizational units directly under a parent.
+func (o *orgUnitAccounts) children(ctx context.Context, parentId string) ([]types.OrganizationalUnit, error) {
+	units := []types.OrganizationalUnit{}
+	paginator := organizations.NewListOrganizationalUnitsForParentPaginator(o.Client, &organizations.ListOrganizationalUnitsForParentInput{
+		ParentId: aws.String(parentId),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, output.OrganizationalUnits...)
+	}
+	return units, nil
+}
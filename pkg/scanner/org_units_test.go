@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"aws-resource-discovery/pkg/mocks"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockOrganizationTree sets up an organization with a Workloads unit holding
+// account1 and a nested Sandbox unit holding account2, and account3 under the root.
+func mockOrganizationTree(client *mocks.MockOrganizationsClient) {
+	client.On("ListRoots", mock.Anything, mock.Anything).Return(&organizations.ListRootsOutput{
+		Roots: []types.Root{{Id: aws.String("r-root"), Name: aws.String("Root")}},
+	}, nil)
+	units := map[string][]types.OrganizationalUnit{
+		"r-root":       {{Id: aws.String("ou-workloads"), Name: aws.String("Workloads")}},
+		"ou-workloads": {{Id: aws.String("ou-sandbox"), Name: aws.String("Sandbox")}},
+		"ou-sandbox":   {},
+	}
+	accounts := map[string][]types.Account{
+		"r-root":       {{Id: aws.String("account3")}},
+		"ou-workloads": {{Id: aws.String("account1")}},
+		"ou-sandbox":   {{Id: aws.String("account2")}},
+	}
+	for parentId := range units {
+		client.On("ListOrganizationalUnitsForParent", mock.Anything, &organizations.ListOrganizationalUnitsForParentInput{ParentId: aws.String(parentId)}).Return(&organizations.ListOrganizationalUnitsForParentOutput{
+			OrganizationalUnits: units[parentId],
+		}, nil)
+		client.On("ListAccountsForParent", mock.Anything, &organizations.ListAccountsForParentInput{ParentId: aws.String(parentId)}).Return(&organizations.ListAccountsForParentOutput{
+			Accounts: accounts[parentId],
+		}, nil)
+	}
+}
+
+func TestOrgUnitAccounts_AccountIds(t *testing.T) {
+	mockClient := new(mocks.MockOrganizationsClient)
+	mockOrganizationTree(mockClient)
+	units := &orgUnitAccounts{Client: mockClient}
+
+	ids, err := units.AccountIds(context.TODO(), []string{"Root/Workloads"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"account1": true, "account2": true}, ids)
+
+	ids, err = units.AccountIds(context.TODO(), []string{"ou-sandbox"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"account2": true}, ids)
+
+	ids, err = units.AccountIds(context.TODO(), []string{"Workloads/Sandbox"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"account2": true}, ids)
+
+	ids, err = units.AccountIds(context.TODO(), []string{"Root"})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+}
+
+func TestOrgUnitAccounts_AccountIdsWithUnknownPath(t *testing.T) {
+	mockClient := new(mocks.MockOrganizationsClient)
+	mockOrganizationTree(mockClient)
+	units := &orgUnitAccounts{Client: mockClient}
+
+	_, err := units.AccountIds(context.TODO(), []string{"Root/Legacy"})
+	assert.ErrorContains(t, err, "no organizational unit named Legacy")
+}
+
+func TestOrgUnitAccounts_AccountIdsWithError(t *testing.T) {
+	mockClient := new(mocks.MockOrganizationsClient)
+	mockClient.On("ListAccountsForParent", mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
+	units := &orgUnitAccounts{Client: mockClient}
+
+	_, err := units.AccountIds(context.TODO(), []string{"ou-sandbox"})
+	assert.ErrorContains(t, err, "test error")
+}
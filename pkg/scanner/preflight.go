@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/preflight"
+	"aws-resource-discovery/pkg/utils"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Preflight runs the checks in the caller account and in a sample of the
+// accounts the configuration selects, in one region, before any counting.
+func (s *Scanner) Preflight(ctx context.Context, config config.Config, checks []preflight.Check) ([]preflight.Result, error) {
+	cfg, _, regions, err := s.initializeScan(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.selectedAccounts(cfg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	callerAccountId := ""
+	if !config.ForceAssumeRole {
+		callerAccountId = s.callerAccountId()
+	}
+	accountIds := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		accountIds = append(accountIds, aws.ToString(account.Id))
+	}
+
+	runner := &preflight.Preflight{
+		CredentialsManager: s.CredentialsManager,
+		Config:             cfg,
+		CallerAccountId:    callerAccountId,
+		Region:             preflightRegion(cfg.Region, regions),
+		Checks:             checks,
+	}
+	return runner.Run(ctx, preflight.Sample(accountIds, callerAccountId, config.PreflightSample)), nil
+}
+
+// simulatePolicies records in the report the actions the counters need that
+// the policies of the scanning role deny in each account.
+func (s *Scanner) simulatePolicies(cfg aws.Config, orgAccounts []types.Account, config config.Config, report *interfaces.ScanReport) {
+	ctx := context.TODO()
+	partition := s.Partition
+	if partition == "" {
+		partition = utils.PartitionForRegion(cfg.Region)
+	}
+	simulator := &preflight.Simulator{
+		CredentialsManager: s.CredentialsManager,
+		ClientFactory:      s.IAMClientFactory,
+		Config:             cfg,
+		Partition:          partition,
+		Region:             cfg.Region,
+		Actions:            preflight.Actions(config, partition),
+	}
+	if !config.ForceAssumeRole {
+		if identity, err := s.STSClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err == nil {
+			simulator.CallerAccountId = aws.ToString(identity.Account)
+			simulator.CallerArn = aws.ToString(identity.Arn)
+		}
+	}
+
+	for _, account := range orgAccounts {
+		denied, err := simulator.Simulate(ctx, aws.ToString(account.Id))
+		if err != nil {
+			s.Logger.Logf("Failed to simulate the policies of account %s: %v", aws.ToString(account.Id), err)
+			continue
+		}
+		report.DeniedActions = append(report.DeniedActions, denied...)
+	}
+}
+
+// selectedAccounts returns the accounts a scan of the configuration would scan.
+func (s *Scanner) selectedAccounts(cfg aws.Config, config config.Config) ([]types.Account, error) {
+	if config.AccountId != "" {
+		return []types.Account{{Id: aws.String(config.AccountId)}}, nil
+	}
+	if len(config.IncludeAccounts) > 0 {
+		return listedAccounts(config.IncludeAccounts), nil
+	}
+	return s.organizationAccounts(cfg, config, &interfaces.ScanReport{})
+}
+
+// preflightRegion returns the region of the session when it is scanned, or
+// else the first scanned region.
+func preflightRegion(region string, regions []string) string {
+	if len(regions) == 0 || toSet(regions)[region] {
+		return region
+	}
+	return regions[0]
+}
@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/mocks"
+	"aws-resource-discovery/pkg/preflight"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScanner_Preflight(t *testing.T) {
+	mockSTSClient := new(mocks.MockSTSClient)
+	mockSessionManager := new(mocks.MockSessionManager)
+	mockRegionsManager := new(mocks.MockRegionsManager)
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockLogger := new(mocks.MockLogger)
+
+	mockSessionManager.On("InitializeSessionAndCredentials", mock.Anything, mock.Anything, mock.Anything).Return(aws.Config{Region: "us-east-1"}, aws.Credentials{})
+	mockRegionsManager.On("GetRegions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]string{"eu-west-1", "eu-central-1"}, nil)
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(&sts.GetCallerIdentityOutput{Account: aws.String("999999999999")}, nil)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "123456789012", "eu-west-1").Return(aws.Credentials{AccessKeyID: "member", SecretAccessKey: "secret"}, nil)
+
+	scanner := NewScanner(mockSTSClient, mockSessionManager, mockRegionsManager, mockCredentialsManager, nil, nil, mockLogger)
+	probed := []string{}
+	checks := []preflight.Check{{Name: "ECS", Action: "ecs:ListClusters", Probe: func(ctx context.Context, cfg aws.Config) error {
+		probed = append(probed, cfg.Region)
+		return nil
+	}}}
+
+	results, err := scanner.Preflight(context.TODO(), config.Config{AccountId: "123456789012"}, checks)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "999999999999", results[0].AccountId)
+	assert.Equal(t, "123456789012", results[1].AccountId)
+	assert.True(t, results[1].Passed())
+	assert.Equal(t, []string{"eu-west-1", "eu-west-1"}, probed)
+	mockCredentialsManager.AssertExpectations(t)
+}
+
+func TestPreflightRegion(t *testing.T) {
+	assert.Equal(t, "us-east-1", preflightRegion("us-east-1", []string{"us-west-2", "us-east-1"}))
+	assert.Equal(t, "us-west-2", preflightRegion("us-east-1", []string{"us-west-2"}))
+	assert.Equal(t, "us-east-1", preflightRegion("us-east-1", nil))
+}
+
+func TestScanner_simulatePolicies(t *testing.T) {
+	mockSTSClient := new(mocks.MockSTSClient)
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockIAMClient := new(mocks.MockIAMClient)
+	mockLogger := new(mocks.MockLogger)
+
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(&sts.GetCallerIdentityOutput{
+		Account: aws.String("111111111111"),
+		Arn:     aws.String("arn:aws:sts::111111111111:assumed-role/Admin/session"),
+	}, nil)
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, "222222222222", "us-east-1").Return(aws.Credentials{}, errors.New("test error"))
+	// The actions follow the partition of the caller, not of the region
+	mockIAMClient.On("SimulatePrincipalPolicy", mock.Anything, mock.MatchedBy(func(input *iam.SimulatePrincipalPolicyInput) bool {
+		return !slices.Contains(input.ActionNames, "ecr-public:DescribeRepositories")
+	})).Return(&iam.SimulatePrincipalPolicyOutput{
+		EvaluationResults: []iamtypes.EvaluationResult{
+			{EvalActionName: aws.String("ecs:ListClusters"), EvalDecision: iamtypes.PolicyEvaluationDecisionTypeImplicitDeny},
+		},
+	}, nil)
+	mockIAMClient.On("GetRole", mock.Anything, mock.Anything).Return(nil, errors.New("access denied"))
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	scanner := NewScanner(mockSTSClient, nil, nil, mockCredentialsManager, nil, nil, mockLogger)
+	scanner.IAMClientFactory = func(cfg aws.Config) interfaces.IAMClient { return mockIAMClient }
+	scanner.Partition = "aws-us-gov"
+	report := &interfaces.ScanReport{}
+	accounts := []types.Account{{Id: aws.String("111111111111")}, {Id: aws.String("222222222222")}}
+
+	scanner.simulatePolicies(aws.Config{Region: "us-east-1"}, accounts, config.Config{}, report)
+
+	assert.Equal(t, []interfaces.DeniedAction{
+		{AccountId: "111111111111", Action: "ecs:ListClusters", DeniedBy: interfaces.DeniedByIdentityPolicy, Decision: "implicitDeny"},
+	}, report.DeniedActions)
+	mockLogger.AssertCalled(t, "Logf", "Failed to simulate the policies of account %s: %v", "222222222222", mock.Anything)
+}
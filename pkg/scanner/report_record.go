@@ -0,0 +1,51 @@
+package scanner
+
+// ReportHeader names the columns of the CSV report. The kind of a record says
+// which of the other columns it fills in:
+//   - count: the count of a resource type and its source, followed by its peak
+//     and average when a lookback window is set
+//   - peak-adjustment: the Auto Scaling instances in service at the peak of the
+//     window on top of the current ones, in the peak column
+//   - discrepancy: the native count, followed in the detail by the CloudControl
+//     count and samples of the identifiers only one of them returned
+//   - filtered: the units removed by the tag filter named in the key
+//   - unfiltered: the units left in the count because their tags could not be
+//     read by the tag filters
+//   - group: the units of the billing category in the detail with the value of
+//     the grouping tag named in the key
+//   - message: a message about the scan, in the detail
+//
+// The organizational unit column is inserted by the organization scanner.
+var ReportHeader = []string{"kind", "account", "organizational_unit", "region", "resource_type", "count", "source", "peak", "average", "key", "detail"}
+
+const (
+	kindCount          = "count"
+	kindPeakAdjustment = "peak-adjustment"
+	kindDiscrepancy    = "discrepancy"
+	kindFiltered       = "filtered"
+	kindUnfiltered     = "unfiltered"
+	kindGroup          = "group"
+)
+
+// ouColumn is the index of the organizational unit column in ReportHeader.
+const ouColumn = 2
+
+// reportRecord is a record of the CSV report without its organizational unit.
+type reportRecord struct {
+	kind         string
+	account      string
+	region       string
+	resourceType string
+	count        string
+	source       string
+	peak         string
+	average      string
+	key          string
+	detail       string
+}
+
+// columns returns the record in the column order of ReportHeader, without the
+// organizational unit column.
+func (r reportRecord) columns() []string {
+	return []string{r.kind, r.account, r.region, r.resourceType, r.count, r.source, r.peak, r.average, r.key, r.detail}
+}
@@ -0,0 +1,212 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"aws-resource-discovery/pkg/config"
+	"aws-resource-discovery/pkg/interfaces"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2"
+)
+
+// resourceExplorerTypes maps the Resource Explorer resource types to the counter
+// classes whose count they can replace.
+var resourceExplorerTypes = map[string]string{
+	"dynamodb:table":                "AWS::DynamoDB::Table",
+	"ec2:instance":                  "AWS::EC2::Instance",
+	"ec2:volume":                    "AWS::EC2::Volume",
+	"elasticfilesystem:file-system": "AWS::EFS::FileSystem",
+	"lambda:function":               "AWS::Lambda::Function",
+	"rds:db":                        "AWS::RDS::DBInstance",
+	"s3:bucket":                     "AWS::S3::Bucket",
+}
+
+// globalResourceTypes are counted once per account instead of once per region.
+var globalResourceTypes = map[string]bool{
+	"AWS::S3::Bucket":            true,
+	"AWS::ECR::PublicRepository": true,
+}
+
+// allRegions is the region part of the keys that cover every region of an account.
+const allRegions = "*"
+
+// ResourceExplorerSource counts resources of the organization through a Resource
+// Explorer aggregator index. Counts are only reported for accounts and regions that
+// have an index and for queries Resource Explorer could answer completely.
+type ResourceExplorerSource struct {
+	Client      interfaces.ResourceExplorerClient
+	ViewArn     string
+	OrgAccounts []types.Account
+	Regions     []string
+	indexed     map[string]bool
+	counts      map[string]int
+	covered     map[string]bool
+}
+
+func NewResourceExplorerSource(client interfaces.ResourceExplorerClient, viewArn string, orgAccounts []types.Account, regions []string) *ResourceExplorerSource {
+	return &ResourceExplorerSource{
+		Client:      client,
+		ViewArn:     viewArn,
+		OrgAccounts: orgAccounts,
+		Regions:     regions,
+		indexed:     map[string]bool{},
+		counts:      map[string]int{},
+		covered:     map[string]bool{},
+	}
+}
+
+func (s *ResourceExplorerSource) Name() string {
+	return config.SourceResourceExplorer
+}
+
+// Load lists the indexes of the organization and counts every supported resource type.
+func (s *ResourceExplorerSource) Load(ctx context.Context) error {
+	if err := s.loadIndexes(ctx); err != nil {
+		return fmt.Errorf("failed to list Resource Explorer indexes: %w", err)
+	}
+
+	resourceTypes := make([]string, 0, len(resourceExplorerTypes))
+	for resourceType := range resourceExplorerTypes {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	for _, resourceType := range resourceTypes {
+		if err := s.loadType(ctx, resourceType, resourceExplorerTypes[resourceType]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ResourceExplorerSource) Count(accountId, region, resourceType string) (int, bool) {
+	if region == "" {
+		for _, scannedRegion := range s.Regions {
+			if !s.indexed[accountId+"/"+scannedRegion] {
+				return 0, false
+			}
+		}
+		region = allRegions
+	} else if !s.indexed[accountId+"/"+region] {
+		return 0, false
+	}
+
+	if !s.covered[countKey(accountId, region, resourceType)] && !s.covered[countKey(accountId, allRegions, resourceType)] {
+		return 0, false
+	}
+	return s.counts[countKey(accountId, region, resourceType)], true
+}
+
+// loadIndexes records the accounts and regions that have a Resource Explorer index.
+func (s *ResourceExplorerSource) loadIndexes(ctx context.Context) error {
+	for start := 0; start < len(s.OrgAccounts); start += 10 {
+		end := min(start+10, len(s.OrgAccounts))
+		accountIds := []string{}
+		for _, account := range s.OrgAccounts[start:end] {
+			accountIds = append(accountIds, aws.ToString(account.Id))
+		}
+
+		input := &resourceexplorer2.ListIndexesForMembersInput{AccountIdList: accountIds}
+		for {
+			output, err := s.Client.ListIndexesForMembers(ctx, input)
+			if err != nil {
+				return err
+			}
+			for _, index := range output.Indexes {
+				s.indexed[aws.ToString(index.AccountId)+"/"+aws.ToString(index.Region)] = true
+			}
+			if output.NextToken == nil {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+	}
+	return nil
+}
+
+// loadType counts a resource type across the organization. Queries matching more
+// resources than Resource Explorer returns are split by account and then by region.
+func (s *ResourceExplorerSource) loadType(ctx context.Context, resourceType, counterClass string) error {
+	query := "resourcetype:" + resourceType
+	complete, err := s.search(ctx, query, counterClass)
+	if err != nil {
+		return err
+	}
+	if complete {
+		for _, account := range s.OrgAccounts {
+			s.covered[countKey(aws.ToString(account.Id), allRegions, counterClass)] = true
+		}
+		return nil
+	}
+
+	for _, account := range s.OrgAccounts {
+		accountId := aws.ToString(account.Id)
+		accountQuery := fmt.Sprintf("%s accountid:%s", query, accountId)
+		complete, err := s.search(ctx, accountQuery, counterClass)
+		if err != nil {
+			return err
+		}
+		if complete {
+			s.covered[countKey(accountId, allRegions, counterClass)] = true
+			continue
+		}
+		if globalResourceTypes[counterClass] {
+			continue
+		}
+
+		for _, region := range s.Regions {
+			complete, err := s.search(ctx, fmt.Sprintf("%s region:%s", accountQuery, region), counterClass)
+			if err != nil {
+				return err
+			}
+			if complete {
+				s.covered[countKey(accountId, region, counterClass)] = true
+			}
+		}
+	}
+	return nil
+}
+
+// search counts the resources matching a query by account and region. It returns
+// false without counting when Resource Explorer cannot return every match.
+func (s *ResourceExplorerSource) search(ctx context.Context, query, counterClass string) (bool, error) {
+	input := &resourceexplorer2.SearchInput{
+		QueryString: aws.String(query),
+		MaxResults:  aws.Int32(1000),
+	}
+	if s.ViewArn != "" {
+		input.ViewArn = aws.String(s.ViewArn)
+	}
+
+	found := map[[2]string]int{}
+	for {
+		output, err := s.Client.Search(ctx, input)
+		if err != nil {
+			return false, fmt.Errorf("failed to search Resource Explorer for %q: %w", query, err)
+		}
+		if output.Count != nil && !aws.ToBool(output.Count.Complete) {
+			return false, nil
+		}
+		for _, resource := range output.Resources {
+			found[[2]string{aws.ToString(resource.OwningAccountId), aws.ToString(resource.Region)}]++
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	for accountRegion, count := range found {
+		s.counts[countKey(accountRegion[0], accountRegion[1], counterClass)] += count
+		s.counts[countKey(accountRegion[0], allRegions, counterClass)] += count
+	}
+	return true, nil
+}
+
+func countKey(accountId, region, resourceType string) string {
+	return accountId + "/" + region + "/" + resourceType
+}
@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"aws-resource-discovery/pkg/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2"
+	explorer_types "github.com/aws/aws-sdk-go-v2/service/resourceexplorer2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func explorerResources(accountId, region string, count int) []explorer_types.Resource {
+	resources := []explorer_types.Resource{}
+	for i := 0; i < count; i++ {
+		resources = append(resources, explorer_types.Resource{OwningAccountId: aws.String(accountId), Region: aws.String(region)})
+	}
+	return resources
+}
+
+func searchQuery(query string) interface{} {
+	return mock.MatchedBy(func(input *resourceexplorer2.SearchInput) bool {
+		return aws.ToString(input.QueryString) == query
+	})
+}
+
+func TestResourceExplorerSource_Load(t *testing.T) {
+	mockClient := new(mocks.MockResourceExplorerClient)
+	accounts := []types.Account{{Id: aws.String("111111111111")}, {Id: aws.String("222222222222")}}
+	source := NewResourceExplorerSource(mockClient, "", accounts, []string{"us-east-1", "us-west-2"})
+
+	mockClient.On("ListIndexesForMembers", mock.Anything, mock.Anything).Return(&resourceexplorer2.ListIndexesForMembersOutput{
+		Indexes: []explorer_types.MemberIndex{
+			{AccountId: aws.String("111111111111"), Region: aws.String("us-east-1")},
+			{AccountId: aws.String("111111111111"), Region: aws.String("us-west-2")},
+			{AccountId: aws.String("222222222222"), Region: aws.String("us-east-1")},
+		},
+	}, nil).Once()
+
+	complete := &explorer_types.ResourceCount{Complete: aws.Bool(true)}
+	incomplete := &explorer_types.ResourceCount{Complete: aws.Bool(false)}
+
+	// EC2 instances are answered by a single query
+	mockClient.On("Search", mock.Anything, searchQuery("resourcetype:ec2:instance")).Return(&resourceexplorer2.SearchOutput{
+		Count:     complete,
+		Resources: append(explorerResources("111111111111", "us-east-1", 2), explorerResources("222222222222", "us-east-1", 1)...),
+	}, nil).Once()
+
+	// EC2 volumes are split by account, and then by region for the first account
+	mockClient.On("Search", mock.Anything, searchQuery("resourcetype:ec2:volume")).Return(&resourceexplorer2.SearchOutput{Count: incomplete}, nil).Once()
+	mockClient.On("Search", mock.Anything, searchQuery("resourcetype:ec2:volume accountid:111111111111")).Return(&resourceexplorer2.SearchOutput{Count: incomplete}, nil).Once()
+	mockClient.On("Search", mock.Anything, searchQuery("resourcetype:ec2:volume accountid:111111111111 region:us-east-1")).Return(&resourceexplorer2.SearchOutput{
+		Count:     complete,
+		Resources: explorerResources("111111111111", "us-east-1", 4),
+	}, nil).Once()
+	mockClient.On("Search", mock.Anything, searchQuery("resourcetype:ec2:volume accountid:111111111111 region:us-west-2")).Return(&resourceexplorer2.SearchOutput{Count: incomplete}, nil).Once()
+	mockClient.On("Search", mock.Anything, searchQuery("resourcetype:ec2:volume accountid:222222222222")).Return(&resourceexplorer2.SearchOutput{
+		Count:     complete,
+		Resources: explorerResources("222222222222", "us-east-1", 5),
+	}, nil).Once()
+
+	// S3 buckets are counted in their own region and looked up for all regions
+	mockClient.On("Search", mock.Anything, searchQuery("resourcetype:s3:bucket")).Return(&resourceexplorer2.SearchOutput{
+		Count:     complete,
+		Resources: append(explorerResources("111111111111", "us-east-1", 1), explorerResources("111111111111", "us-west-2", 2)...),
+	}, nil).Once()
+
+	mockClient.On("Search", mock.Anything, mock.Anything).Return(&resourceexplorer2.SearchOutput{Count: complete}, nil)
+
+	assert.NoError(t, source.Load(context.TODO()))
+
+	count, ok := source.Count("111111111111", "us-east-1", "AWS::EC2::Instance")
+	assert.True(t, ok)
+	assert.Equal(t, 2, count)
+
+	count, ok = source.Count("111111111111", "us-west-2", "AWS::EC2::Instance")
+	assert.True(t, ok)
+	assert.Equal(t, 0, count)
+
+	// Regions without an index are not covered
+	_, ok = source.Count("222222222222", "us-west-2", "AWS::EC2::Instance")
+	assert.False(t, ok)
+
+	count, ok = source.Count("111111111111", "us-east-1", "AWS::EC2::Volume")
+	assert.True(t, ok)
+	assert.Equal(t, 4, count)
+
+	_, ok = source.Count("111111111111", "us-west-2", "AWS::EC2::Volume")
+	assert.False(t, ok)
+
+	count, ok = source.Count("222222222222", "us-east-1", "AWS::EC2::Volume")
+	assert.True(t, ok)
+	assert.Equal(t, 5, count)
+
+	count, ok = source.Count("111111111111", "", "AWS::S3::Bucket")
+	assert.True(t, ok)
+	assert.Equal(t, 3, count)
+
+	_, ok = source.Count("222222222222", "", "AWS::S3::Bucket")
+	assert.False(t, ok)
+
+	// Unsupported resource types are never covered
+	_, ok = source.Count("111111111111", "us-east-1", "AWS::ECS::Cluster")
+	assert.False(t, ok)
+}
+
+func TestResourceExplorerSource_LoadWithError(t *testing.T) {
+	mockClient := new(mocks.MockResourceExplorerClient)
+	source := NewResourceExplorerSource(mockClient, "", []types.Account{{Id: aws.String("111111111111")}}, []string{"us-east-1"})
+
+	expectedError := errors.New("test error")
+	mockClient.On("ListIndexesForMembers", mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+
+	err := source.Load(context.TODO())
+	assert.ErrorIs(t, err, expectedError)
+	assert.Equal(t, "resource-explorer", source.Name())
+}
+
+func TestResourceExplorerSource_searchWithView(t *testing.T) {
+	mockClient := new(mocks.MockResourceExplorerClient)
+	source := NewResourceExplorerSource(mockClient, "arn:aws:resource-explorer-2:us-east-1:111111111111:view/org/1", nil, nil)
+
+	mockClient.On("Search", mock.Anything, mock.MatchedBy(func(input *resourceexplorer2.SearchInput) bool {
+		return aws.ToString(input.ViewArn) == source.ViewArn && aws.ToString(input.NextToken) == ""
+	})).Return(&resourceexplorer2.SearchOutput{
+		Resources: explorerResources("111111111111", "us-east-1", 1),
+		NextToken: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("Search", mock.Anything, mock.MatchedBy(func(input *resourceexplorer2.SearchInput) bool {
+		return aws.ToString(input.NextToken) == "next"
+	})).Return(&resourceexplorer2.SearchOutput{
+		Resources: explorerResources("111111111111", "us-east-1", 2),
+	}, nil).Once()
+
+	complete, err := source.search(context.TODO(), "resourcetype:rds:db", "AWS::RDS::DBInstance")
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, 3, source.counts[countKey("111111111111", "us-east-1", "AWS::RDS::DBInstance")])
+	assert.Equal(t, 3, source.counts[countKey("111111111111", allRegions, "AWS::RDS::DBInstance")])
+	mockClient.AssertExpectations(t)
+}
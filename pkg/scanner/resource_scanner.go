@@ -2,20 +2,31 @@ package scanner
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
+	"aws-resource-discovery/pkg/config"
 	"aws-resource-discovery/pkg/counter"
 	"aws-resource-discovery/pkg/interfaces"
+	"aws-resource-discovery/pkg/utils"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/aws/aws-sdk-go-v2/service/ecrpublic"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 )
 
 type ResourceScanner struct {
@@ -23,11 +34,32 @@ type ResourceScanner struct {
 	Credentials aws.Credentials
 	AccountId   string
 	Region      string
+	// Global counts the resources of the account that are not regional, such
+	// as S3 buckets, in this region. It is set in one region per account.
+	Global      bool
 	Logger      interfaces.Logger
 	Totals      *interfaces.ResourceTotals
+	UserConfig  config.Config
+	CountSource interfaces.CountSource
+	Report      *interfaces.ScanReport
+	// Inventory receives a record of every counted resource when set.
+	Inventory interfaces.Logger
+	// Capacity receives the capacity records of Auto Scaling groups when set.
+	Capacity interfaces.Logger
+	// Retries is the retry configuration of the clients of the scanner.
+	Retries *utils.Retries
 }
 
-const GLOBAL_SCAN_REGION = "us-east-1"
+// CapacityHeader names the columns of the capacity report. Peak and p95 are
+// left empty without a lookback window.
+var CapacityHeader = []string{"account", "region", "auto_scaling_group", "min", "max", "desired", "in_service", "peak", "p95"}
+
+// autoScalingGroupType is the counter class of the Auto Scaling group counter,
+// whose instances are also counted as EC2 instances.
+const autoScalingGroupType = "AWS::AutoScaling::AutoScalingGroup"
+
+// maxDiscrepancySamples limits the identifiers reported for each side of a discrepancy.
+const maxDiscrepancySamples = 5
 
 func (s *ResourceScanner) Call() {
 	s.scanResources()
@@ -35,37 +67,149 @@ func (s *ResourceScanner) Call() {
 
 func (s *ResourceScanner) scanResources() {
 	ctx := context.Background()
-	s.Session = s.createSession(ctx)
-	client := cloudcontrol.NewFromConfig(s.Session)
-	eksClient := eks.NewFromConfig(s.Session)
-	ec2Client := ec2.NewFromConfig(s.Session)
-	ecsClient := ecs.NewFromConfig(s.Session)
-	ecrClient := ecr.NewFromConfig(s.Session)
+	// Counts the count source covers need no session in the account.
+	if !s.sourceCovers() {
+		s.Session = s.createSession(ctx)
+		if s.regionBlocked(ctx, ec2.NewFromConfig(s.Session)) {
+			return
+		}
+	}
+	counters := s.counters(s.Session)
+
+	if s.CountSource != nil {
+		counters = s.withSourceCounts(counters)
+	}
+
+	if s.UserConfig.Lookback > 0 {
+		counters = counter.WithHistory(counters, cloudwatch.NewFromConfig(s.Session), s.UserConfig.Lookback)
+	}
+
+	// The records are only kept for the inventory and the grouping by tag
+	if s.Inventory == nil && s.UserConfig.GroupByTag == "" {
+		counters = counter.WithoutRecords(counters)
+	}
+
+	resourceResults := s.runCounters(counters)
+
+	for _, result := range resourceResults {
+		s.updateTotals(result.CounterClass, result.Count)
+		if s.UserConfig.Lookback > 0 {
+			s.updatePeakTotals(result)
+		}
+	}
+	if s.UserConfig.GroupByTag != "" {
+		s.updateTagTotals(resourceResults)
+	}
+
+	s.logResults(resourceResults)
+}
+
+// counters returns the counters of the account and region with the clients of
+// the session, wrapped for reconciliation and tag filtering as configured.
+func (s *ResourceScanner) counters(session aws.Config) []interfaces.Counter {
+	client := cloudcontrol.NewFromConfig(session)
+	eksClient := eks.NewFromConfig(session)
+	ec2Client := ec2.NewFromConfig(session)
+	ecsClient := ecs.NewFromConfig(session)
+	ecrClient := ecr.NewFromConfig(session)
+	autoScalingClient := autoscaling.NewFromConfig(session)
+	cloudWatchClient := cloudwatch.NewFromConfig(session)
 	var counters []interfaces.Counter
 
-	// Only add the BucketCounter if the region is us-east-1
-	// ECR Public is only available in us-east-1
-	if s.Region == GLOBAL_SCAN_REGION {
+	// Global resources are only counted in one region of the account
+	// ECR Public is only available in us-east-1 of the aws partition
+	if s.Global {
 		counters = append(counters, counter.NewBucketCounter(client))
 
 		// Saves on API calls if we don't need to scan ECR Public
-		client_ecrpublic := ecrpublic.NewFromConfig(s.Session)
-		counters = append(counters, counter.NewEcrPublicCounter(client_ecrpublic))
+		partition := utils.PartitionForRegion(s.Region)
+		if utils.HasECRPublic(partition) {
+			client_ecrpublic := ecrpublic.NewFromConfig(session, func(o *ecrpublic.Options) {
+				o.Region = utils.GlobalRegion(partition)
+			})
+			counters = append(counters, counter.NewEcrPublicCounter(client_ecrpublic))
+		}
+	}
+
+	if s.UserConfig.CountStrategy == config.StrategyCloudControl {
+		counters = append(counters,
+			counter.NewEc2Counter(client),
+			counter.NewDynamoDbCounter(client),
+			counter.NewEbsCounter(client),
+			counter.NewEfsCounter(client),
+			counter.NewLambdaCounter(client),
+			counter.NewRdsCounter(client))
+	} else {
+		counters = append(counters,
+			counter.NewNativeEc2Counter(ec2Client),
+			counter.NewNativeDynamoDbCounter(dynamodb.NewFromConfig(session)),
+			counter.NewNativeEbsCounter(ec2Client),
+			counter.NewNativeEfsCounter(efs.NewFromConfig(session)),
+			counter.NewNativeLambdaCounter(lambda.NewFromConfig(session)),
+			counter.NewNativeRdsCounter(rds.NewFromConfig(session)))
 	}
 
 	counters = append(counters,
-		counter.NewEc2Counter(client),
-		counter.NewDynamoDbCounter(client),
-		counter.NewEbsCounter(client),
 		counter.NewEcrCounter(ecrClient),
 		counter.NewEcsCounter(ecsClient),
-		counter.NewEfsCounter(client),
 		counter.NewEksCounter(eksClient, ec2Client),
-		counter.NewLambdaCounter(client),
-		counter.NewRdsCounter(client))
+		counter.NewAutoScalingCounter(autoScalingClient, cloudWatchClient, s.UserConfig.Lookback))
 
-	results := make(chan interfaces.CounterResult, len(counters))
+	if s.UserConfig.Reconcile {
+		counters = counter.WithReconciliation(counters, client)
+	}
+
+	// Tag filters also fill in the tags that grouping by tag relies on.
+	if len(s.UserConfig.IncludeTags) > 0 || len(s.UserConfig.ExcludeTags) > 0 || s.UserConfig.GroupByTag != "" {
+		taggingClient := resourcegroupstaggingapi.NewFromConfig(session)
+		counters = counter.WithTagFilters(counters, taggingClient, s.UserConfig.IncludeTags, s.UserConfig.ExcludeTags)
+	}
 
+	return counters
+}
+
+// logResults writes the counter results to the reports of the scanner.
+func (s *ResourceScanner) logResults(resourceResults map[string]interfaces.CounterResult) {
+	for resourceType, result := range resourceResults {
+		// Auto Scaling instances are already counted by the EC2 counter, so
+		// groups are only written to the capacity report, and to the CSV report
+		// as the instances they add at their peak.
+		if resourceType != autoScalingGroupType {
+			s.Logger.Log(s.record(resourceType, result))
+		} else if s.UserConfig.Lookback > 0 {
+			s.Logger.Log(reportRecord{
+				kind:         kindPeakAdjustment,
+				account:      s.AccountId,
+				region:       s.Region,
+				resourceType: resourceType,
+				source:       result.Source,
+				peak:         strconv.Itoa(max(result.Count, result.Peak) - result.Count),
+			}.columns())
+		}
+		if s.Capacity != nil {
+			for _, detail := range result.Details {
+				s.Capacity.Log(append([]string{s.AccountId, s.Region}, detail...))
+			}
+		}
+		if result.Reconciliation != nil {
+			s.reconcile(resourceType, result)
+		}
+		s.recordFiltered(resourceType, result.Filtered)
+		s.recordUnfiltered(resourceType, result.Unfiltered)
+		if result.PermissionSuggestion != "" && s.Report != nil {
+			s.Report.SuggestPermissions(resourceType, s.AccountId, result.PermissionSuggestion)
+		}
+		if s.Inventory != nil {
+			for _, resource := range result.Resources {
+				s.Inventory.Log(s.inventoryRecord(resourceType, resource))
+			}
+		}
+	}
+}
+
+// runCounters runs the counters and returns their results by counter class.
+func (s *ResourceScanner) runCounters(counters []interfaces.Counter) map[string]interfaces.CounterResult {
+	results := make(chan interfaces.CounterResult, len(counters))
 	for _, cnt := range counters {
 		go func(cnt interfaces.Counter) {
 			cnt.Call()
@@ -73,18 +217,193 @@ func (s *ResourceScanner) scanResources() {
 		}(cnt)
 	}
 
-	resourceResults := make(map[string]int)
-
+	resourceResults := make(map[string]interfaces.CounterResult)
 	for range counters {
 		result := <-results
-		resourceResults[result.CounterClass] = result.Count
-		s.updateTotals(result.CounterClass, result.Count)
+		resourceResults[result.CounterClass] = result
+	}
+	return resourceResults
+}
+
+// regionBlocked probes the region with a call that every scan can make. When
+// it fails with an explicit deny of a service control policy, the region is
+// marked as blocked in the account so that its counters are not run. Other
+// failures of the probe leave the counters to report their own errors.
+func (s *ResourceScanner) regionBlocked(ctx context.Context, client interfaces.EC2Client) bool {
+	_, err := client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{})
+	if !utils.IsSCPDeny(err) {
+		return false
+	}
+	s.Logger.Logf("Skipping region %s denied by a service control policy in account %s: %v", s.Region, s.AccountId, err)
+	if s.Report != nil {
+		s.Report.SetRegionStatus(s.AccountId, s.Region, interfaces.RegionBlocked)
+	}
+	return true
+}
+
+// inventoryRecord builds the inventory CSV record of a counted resource. Tags are
+// written as sorted key=value pairs separated by semicolons.
+func (s *ResourceScanner) inventoryRecord(resourceType string, resource interfaces.ResourceRecord) []string {
+	tags := make([]string, 0, len(resource.Tags))
+	for key, value := range resource.Tags {
+		tags = append(tags, key+"="+value)
+	}
+	sort.Strings(tags)
+
+	count := 1
+	if resource.Count > 0 {
+		count = resource.Count
+	}
+	return []string{s.AccountId, s.Region, resourceType, resource.Identifier, resource.Arn, resource.State, strings.Join(tags, ";"), strconv.Itoa(count)}
+}
+
+// reconcile logs and reports the resources that only one of the native API and
+// CloudControl returned.
+func (s *ResourceScanner) reconcile(resourceType string, result interfaces.CounterResult) {
+	reconciliation := result.Reconciliation
+	if reconciliation.Error != nil {
+		s.Logger.Logf("Failed to reconcile %s in account %s in region %s with CloudControl: %v", resourceType, s.AccountId, s.Region, reconciliation.Error)
+		return
+	}
+	mismatched := len(reconciliation.OnlyNative) + len(reconciliation.OnlyCloudControl)
+	if mismatched == 0 {
+		return
+	}
+
+	discrepancy := interfaces.Discrepancy{
+		AccountId:         s.AccountId,
+		Region:            s.Region,
+		ResourceType:      resourceType,
+		NativeCount:       result.Count,
+		CloudControlCount: reconciliation.CloudControlCount,
+		Mismatched:        mismatched,
+		OnlyNative:        samples(reconciliation.OnlyNative),
+		OnlyCloudControl:  samples(reconciliation.OnlyCloudControl),
+	}
+	s.Logger.Log(reportRecord{
+		kind:         kindDiscrepancy,
+		account:      s.AccountId,
+		region:       s.Region,
+		resourceType: resourceType,
+		count:        strconv.Itoa(discrepancy.NativeCount),
+		source:       counter.SourceNative,
+		detail: fmt.Sprintf("cloudcontrol=%d;only_native=%s;only_cloudcontrol=%s",
+			discrepancy.CloudControlCount,
+			strings.Join(discrepancy.OnlyNative, " "),
+			strings.Join(discrepancy.OnlyCloudControl, " ")),
+	}.columns())
+	if s.Report != nil {
+		s.Report.Discrepancies = append(s.Report.Discrepancies, discrepancy)
+	}
+}
+
+// recordFiltered logs and reports the number of units each tag filter removed.
+func (s *ResourceScanner) recordFiltered(resourceType string, filtered map[string]int) {
+	for _, filter := range sortedKeys(filtered) {
+		s.Logger.Log(reportRecord{
+			kind:         kindFiltered,
+			account:      s.AccountId,
+			region:       s.Region,
+			resourceType: resourceType,
+			count:        strconv.Itoa(filtered[filter]),
+			key:          filter,
+		}.columns())
+		if s.Report != nil {
+			if s.Report.Filtered == nil {
+				s.Report.Filtered = map[string]int{}
+			}
+			s.Report.Filtered[filter] += filtered[filter]
+		}
+	}
+}
+
+// recordUnfiltered logs and reports the number of units the tag filters could
+// not be applied to.
+func (s *ResourceScanner) recordUnfiltered(resourceType string, unfiltered int) {
+	if unfiltered == 0 {
+		return
+	}
+	s.Logger.Log(reportRecord{
+		kind:         kindUnfiltered,
+		account:      s.AccountId,
+		region:       s.Region,
+		resourceType: resourceType,
+		count:        strconv.Itoa(unfiltered),
+	}.columns())
+	if s.Report != nil {
+		if s.Report.Unfiltered == nil {
+			s.Report.Unfiltered = map[string]int{}
+		}
+		s.Report.Unfiltered[resourceType] += unfiltered
 	}
+}
 
-	for resourceType, resourceCount := range resourceResults {
-		record := []string{s.AccountId, s.Region, resourceType, strconv.Itoa(resourceCount)}
-		s.Logger.Log(record)
+// samples returns up to maxDiscrepancySamples identifiers.
+func samples(identifiers []string) []string {
+	if len(identifiers) > maxDiscrepancySamples {
+		return identifiers[:maxDiscrepancySamples]
 	}
+	return identifiers
+}
+
+// withSourceCounts replaces the counters whose count the count source covers for
+// this account and region.
+func (s *ResourceScanner) withSourceCounts(counters []interfaces.Counter) []interfaces.Counter {
+	replaced := make([]interfaces.Counter, 0, len(counters))
+	for _, cnt := range counters {
+		counterClass := cnt.GetResult().CounterClass
+		if count, ok := s.sourceCount(counterClass); ok {
+			cnt = counter.NewStaticCounter(counterClass, count, s.CountSource.Name())
+		}
+		replaced = append(replaced, cnt)
+	}
+	return replaced
+}
+
+// sourceCount returns the count of a resource type in this account and region
+// from the count source, and whether the source covers it.
+func (s *ResourceScanner) sourceCount(counterClass string) (int, bool) {
+	region := s.Region
+	if globalResourceTypes[counterClass] {
+		region = ""
+	}
+	return s.CountSource.Count(s.AccountId, region, counterClass)
+}
+
+// sourceCovers reports whether the count source covers every resource type
+// counted in this account and region, so that the scan calls no API of the
+// account. Lookback windows are read from CloudWatch in the account.
+func (s *ResourceScanner) sourceCovers() bool {
+	if s.CountSource == nil || s.UserConfig.Lookback > 0 {
+		return false
+	}
+	for _, cnt := range s.counters(aws.Config{}) {
+		if _, ok := s.sourceCount(cnt.GetResult().CounterClass); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// record builds the CSV record of a counter result with the source of the
+// count. In lookback mode it also holds the peak and average count over the
+// window; the average is left empty for resource types without metric history.
+func (s *ResourceScanner) record(resourceType string, result interfaces.CounterResult) []string {
+	record := reportRecord{
+		kind:         kindCount,
+		account:      s.AccountId,
+		region:       s.Region,
+		resourceType: resourceType,
+		count:        strconv.Itoa(result.Count),
+		source:       result.Source,
+	}
+	if s.UserConfig.Lookback > 0 {
+		record.peak = strconv.Itoa(max(result.Count, result.Peak))
+		if result.HasHistory {
+			record.average = strconv.FormatFloat(result.Average, 'f', 1, 64)
+		}
+	}
+	return record.columns()
 }
 
 func (s *ResourceScanner) createSession(ctx context.Context) aws.Config {
@@ -92,39 +411,130 @@ func (s *ResourceScanner) createSession(ctx context.Context) aws.Config {
 		return s.Session
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(s.Region))
+	cfg, err := newSession(ctx, s.UserConfig.Profile, s.Region, s.Credentials, s.Retries)
 	if err != nil {
 		s.Logger.Logf("Failed to initialize AWS session: %v", err)
 	}
+	return cfg
+}
 
-	if s.Credentials.HasKeys() {
-		creds := aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(
-			s.Credentials.AccessKeyID,
-			s.Credentials.SecretAccessKey,
-			s.Credentials.SessionToken,
+// newSession loads the configuration of the profile in a region. Credentials
+// with keys, such as the ones of an assumed role, replace the profile ones.
+func newSession(ctx context.Context, profile, region string, creds aws.Credentials, retries *utils.Retries) (aws.Config, error) {
+	cfg, err := utils.LoadAWSConfig(ctx, profile, region, retries)
+	if creds.HasKeys() {
+		cfg.Credentials = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(
+			creds.AccessKeyID,
+			creds.SecretAccessKey,
+			creds.SessionToken,
 		))
-		cfg.Credentials = creds
 	}
-	return cfg
+	return cfg, err
 }
 
 func (s *ResourceScanner) updateTotals(resourceType string, count int) {
+	addToTotals(s.Totals, resourceType, count)
+}
+
+// updateTagTotals adds the units of the resources of the results to the totals
+// of their grouping tag value, and logs the count of every billing category of
+// every value in the account and region. Counts without resource records are
+// added to the untagged group.
+func (s *ResourceScanner) updateTagTotals(results map[string]interfaces.CounterResult) {
+	byTag := map[string]*interfaces.ResourceTotals{}
+	for _, result := range results {
+		for group, units := range s.tagGroups(result) {
+			if byTag[group] == nil {
+				byTag[group] = &interfaces.ResourceTotals{}
+			}
+			addToTotals(byTag[group], result.CounterClass, units)
+		}
+	}
+
+	if s.Totals.ByTag == nil {
+		s.Totals.ByTag = map[string]*interfaces.ResourceTotals{}
+	}
+	for _, group := range sortedKeys(byTag) {
+		logged := false
+		for _, category := range byTag[group].Categories() {
+			if category.Count == 0 {
+				continue
+			}
+			logged = true
+			s.Logger.Log(reportRecord{
+				kind:    kindGroup,
+				account: s.AccountId,
+				region:  s.Region,
+				count:   strconv.Itoa(category.Count),
+				key:     s.UserConfig.GroupByTag + "=" + group,
+				detail:  category.Name,
+			}.columns())
+		}
+		// Resource types outside the billing categories, such as Auto Scaling
+		// groups, leave no totals.
+		if !logged {
+			continue
+		}
+		if s.Totals.ByTag[group] == nil {
+			s.Totals.ByTag[group] = &interfaces.ResourceTotals{}
+		}
+		s.Totals.ByTag[group].Add(*byTag[group])
+	}
+}
+
+// tagGroups returns the units of a result per value of the grouping tag.
+func (s *ResourceScanner) tagGroups(result interfaces.CounterResult) map[string]int {
+	groups := map[string]int{}
+	grouped := 0
+	for _, resource := range result.Resources {
+		group, ok := resource.Tags[s.UserConfig.GroupByTag]
+		if !ok || group == "" {
+			group = interfaces.UntaggedGroup
+		}
+		units := max(resource.Count, 1)
+		groups[group] += units
+		grouped += units
+	}
+	if remaining := result.Count - grouped; remaining > 0 {
+		groups[interfaces.UntaggedGroup] += remaining
+	}
+	return groups
+}
+
+// updatePeakTotals adds the peak count of a result, or its current count when
+// no higher value was observed, to the peak totals.
+func (s *ResourceScanner) updatePeakTotals(result interfaces.CounterResult) {
+	if s.Totals.Peak == nil {
+		s.Totals.Peak = &interfaces.ResourceTotals{}
+	}
+	peak := max(result.Count, result.Peak)
+
+	// Auto Scaling instances are already counted by the EC2 counter, so only the
+	// difference between the current and peak in-service count is added.
+	if result.CounterClass == autoScalingGroupType {
+		s.Totals.Peak.VirtualMachines += peak - result.Count
+		return
+	}
+	addToTotals(s.Totals.Peak, result.CounterClass, peak)
+}
+
+func addToTotals(totals *interfaces.ResourceTotals, resourceType string, count int) {
 	switch resourceType {
 	case "AWS::S3::Bucket":
-		s.Totals.Buckets += count
+		totals.Buckets += count
 	case "AWS::EKS::Cluster":
-		s.Totals.ContainerHosts += count
+		totals.ContainerHosts += count
 	case "AWS::DynamoDB::Table", "AWS::RDS::DBInstance":
-		s.Totals.Databases += count
+		totals.Databases += count
 	case "AWS::EFS::FileSystem", "AWS::EC2::Volume":
-		s.Totals.NonOsDisks += count
+		totals.NonOsDisks += count
 	case "AWS::ECS::Cluster":
-		s.Totals.ServerlessContainers += count
+		totals.ServerlessContainers += count
 	case "AWS::Lambda::Function":
-		s.Totals.ServerlessFunctions += count
+		totals.ServerlessFunctions += count
 	case "AWS::EC2::Instance":
-		s.Totals.VirtualMachines += count
+		totals.VirtualMachines += count
 	case "AWS::ECR::Repository", "AWS::ECR::PublicRepository":
-		s.Totals.ContainerRegistryImages += count
+		totals.ContainerRegistryImages += count
 	}
 }
@@ -1,12 +1,16 @@
 package scanner
 
 import (
+	"aws-resource-discovery/pkg/counter"
 	"aws-resource-discovery/pkg/interfaces"
 	"aws-resource-discovery/pkg/mocks"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -98,3 +102,310 @@ func TestResourceScanner_updateTotals(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceScanner_updatePeakTotals(t *testing.T) {
+	totals := &interfaces.ResourceTotals{}
+	scanner := &ResourceScanner{Totals: totals}
+
+	scanner.updatePeakTotals(interfaces.CounterResult{CounterClass: "AWS::EC2::Instance", Count: 10})
+	scanner.updatePeakTotals(interfaces.CounterResult{CounterClass: "AWS::AutoScaling::AutoScalingGroup", Count: 4, Peak: 12})
+	scanner.updatePeakTotals(interfaces.CounterResult{CounterClass: "AWS::Lambda::Function", Count: 3})
+
+	assert.NotNil(t, totals.Peak)
+	assert.Equal(t, 18, totals.Peak.VirtualMachines)
+	assert.Equal(t, 3, totals.Peak.ServerlessFunctions)
+	assert.Equal(t, 0, totals.VirtualMachines)
+}
+
+func TestResourceScanner_record(t *testing.T) {
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1"}
+	result := interfaces.CounterResult{Count: 4, Peak: 9, Average: 5, HasHistory: true, Source: "native"}
+
+	assert.Equal(t, []string{"count", "123456789012", "us-east-1", "AWS::EKS::Cluster", "4", "native", "", "", "", ""}, scanner.record("AWS::EKS::Cluster", result))
+
+	scanner.UserConfig.Lookback = time.Hour
+	assert.Equal(t, []string{"count", "123456789012", "us-east-1", "AWS::EKS::Cluster", "4", "native", "9", "5.0", "", ""}, scanner.record("AWS::EKS::Cluster", result))
+	assert.Equal(t, []string{"count", "123456789012", "us-east-1", "AWS::S3::Bucket", "2", "cloudcontrol", "2", "", "", ""}, scanner.record("AWS::S3::Bucket", interfaces.CounterResult{Count: 2, Source: "cloudcontrol"}))
+	// An idle history averages 0 and is still reported
+	assert.Equal(t, []string{"count", "123456789012", "us-east-1", "AWS::EKS::Cluster", "0", "native", "0", "0.0", "", ""}, scanner.record("AWS::EKS::Cluster", interfaces.CounterResult{HasHistory: true, Source: "native"}))
+}
+
+func TestResourceScanner_withSourceCounts(t *testing.T) {
+	mockSource := new(mocks.MockCountSource)
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-west-2", CountSource: mockSource}
+
+	mockSource.On("Name").Return("resource-explorer")
+	mockSource.On("Count", "123456789012", "us-west-2", "AWS::EC2::Instance").Return(7, true)
+	mockSource.On("Count", "123456789012", "us-west-2", "AWS::ECS::Cluster").Return(0, false)
+	mockSource.On("Count", "123456789012", "", "AWS::S3::Bucket").Return(3, true)
+
+	counters := scanner.withSourceCounts([]interfaces.Counter{
+		counter.NewEc2Counter(nil),
+		counter.NewEcsCounter(nil),
+		counter.NewBucketCounter(nil),
+	})
+
+	assert.IsType(t, &counter.StaticCounter{}, counters[0])
+	assert.Equal(t, interfaces.CounterResult{Count: 7, CounterClass: "AWS::EC2::Instance", Source: "resource-explorer"}, counters[0].GetResult())
+	assert.IsType(t, &counter.EcsCounter{}, counters[1])
+	assert.Equal(t, 3, counters[2].GetResult().Count)
+	mockSource.AssertExpectations(t)
+}
+
+func TestResourceScanner_sourceCovers(t *testing.T) {
+	mockSource := new(mocks.MockCountSource)
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-west-2", CountSource: mockSource}
+
+	mockSource.On("Count", "123456789012", mock.Anything, mock.Anything).Return(1, true)
+
+	assert.True(t, scanner.sourceCovers())
+
+	// CloudWatch is read in the account for the lookback window
+	scanner.UserConfig.Lookback = time.Hour
+	assert.False(t, scanner.sourceCovers())
+	assert.False(t, (&ResourceScanner{Region: "us-west-2"}).sourceCovers())
+}
+
+func TestResourceScanner_sourceCoversMissingType(t *testing.T) {
+	mockSource := new(mocks.MockCountSource)
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-west-2", CountSource: mockSource}
+
+	mockSource.On("Count", "123456789012", "us-west-2", "AWS::ECS::Cluster").Return(0, false)
+	mockSource.On("Count", "123456789012", mock.Anything, mock.Anything).Return(1, true)
+
+	assert.False(t, scanner.sourceCovers())
+}
+
+func TestResourceScanner_reconcile(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	report := &interfaces.ScanReport{}
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger, Report: report}
+
+	mockLogger.On("Log", []string{"discrepancy", "123456789012", "us-east-1", "AWS::EC2::Instance", "7", "native", "", "", "", "cloudcontrol=6;only_native=i-1 i-2 i-3 i-4 i-5;only_cloudcontrol=i-9"}).Return(nil).Once()
+
+	scanner.reconcile("AWS::EC2::Instance", interfaces.CounterResult{
+		Count: 7,
+		Reconciliation: &interfaces.Reconciliation{
+			CloudControlCount: 6,
+			OnlyNative:        []string{"i-1", "i-2", "i-3", "i-4", "i-5", "i-6"},
+			OnlyCloudControl:  []string{"i-9"},
+		},
+	})
+
+	// Matching listings are not reported
+	scanner.reconcile("AWS::EC2::Volume", interfaces.CounterResult{
+		Count:          2,
+		Reconciliation: &interfaces.Reconciliation{CloudControlCount: 2, OnlyNative: []string{}, OnlyCloudControl: []string{}},
+	})
+
+	assert.Len(t, report.Discrepancies, 1)
+	discrepancy := report.Discrepancies[0]
+	assert.Equal(t, 7, discrepancy.Mismatched)
+	assert.Equal(t, 100.0, discrepancy.Difference())
+	assert.Len(t, report.DiscrepanciesAbove(100), 0)
+	assert.Len(t, report.DiscrepanciesAbove(50), 1)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestResourceScanner_runCounters(t *testing.T) {
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1"}
+	failed := counter.NewStaticCounter("AWS::S3::Bucket", 0, counter.SourceCloudControl)
+	failed.Result.Error = &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform: s3:ListAllMyBuckets with an explicit deny in a service control policy"}
+
+	results := scanner.runCounters([]interfaces.Counter{
+		failed,
+		counter.NewStaticCounter("AWS::EC2::Instance", 3, counter.SourceNative),
+		counter.NewStaticCounter("AWS::Lambda::Function", 2, counter.SourceNative),
+	})
+
+	// A denied counter does not stop the others
+	assert.Error(t, results["AWS::S3::Bucket"].Error)
+	assert.Equal(t, 3, results["AWS::EC2::Instance"].Count)
+	assert.Equal(t, 2, results["AWS::Lambda::Function"].Count)
+}
+
+func TestResourceScanner_regionBlocked(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockEC2Client := new(mocks.MockEC2Client)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx := context.TODO()
+
+	t.Run("Region blocked by a service control policy", func(t *testing.T) {
+		report := &interfaces.ScanReport{}
+		scanner := &ResourceScanner{AccountId: "123456789012", Region: "sa-east-1", Logger: mockLogger, Report: report}
+		mockEC2Client.On("DescribeAvailabilityZones", mock.Anything, mock.Anything).Return(nil, &smithy.GenericAPIError{
+			Code:    "UnauthorizedOperation",
+			Message: "You are not authorized to perform: ec2:DescribeAvailabilityZones with an explicit deny in a service control policy",
+		}).Once()
+
+		assert.True(t, scanner.regionBlocked(ctx, mockEC2Client))
+		assert.Equal(t, map[string]string{"sa-east-1": interfaces.RegionBlocked}, report.RegionStatuses["123456789012"])
+	})
+
+	t.Run("Probe denied by the identity policy", func(t *testing.T) {
+		report := &interfaces.ScanReport{}
+		scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger, Report: report}
+		mockEC2Client.On("DescribeAvailabilityZones", mock.Anything, mock.Anything).Return(nil, &smithy.GenericAPIError{
+			Code:    "UnauthorizedOperation",
+			Message: "You are not authorized to perform: ec2:DescribeAvailabilityZones because no identity-based policy allows the ec2:DescribeAvailabilityZones action",
+		}).Once()
+
+		assert.False(t, scanner.regionBlocked(ctx, mockEC2Client))
+		assert.Nil(t, report.RegionStatuses)
+	})
+
+	t.Run("Region allowed", func(t *testing.T) {
+		report := &interfaces.ScanReport{}
+		scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger, Report: report}
+		mockEC2Client.On("DescribeAvailabilityZones", mock.Anything, mock.Anything).Return(&ec2.DescribeAvailabilityZonesOutput{}, nil).Once()
+
+		assert.False(t, scanner.regionBlocked(ctx, mockEC2Client))
+		assert.Nil(t, report.RegionStatuses)
+	})
+}
+
+func TestResourceScanner_inventoryRecord(t *testing.T) {
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1"}
+
+	assert.Equal(t,
+		[]string{"123456789012", "us-east-1", "AWS::EC2::Instance", "i-0123", "", "running", "env=prod;team=web", "1"},
+		scanner.inventoryRecord("AWS::EC2::Instance", interfaces.ResourceRecord{
+			Identifier: "i-0123",
+			State:      "running",
+			Tags:       map[string]string{"team": "web", "env": "prod"},
+		}))
+	assert.Equal(t,
+		[]string{"123456789012", "us-east-1", "AWS::ECS::Cluster", "ecs-svc/1", "arn:aws:ecs:us-east-1:123456789012:service/web", "PRIMARY", "", "6"},
+		scanner.inventoryRecord("AWS::ECS::Cluster", interfaces.ResourceRecord{
+			Identifier: "ecs-svc/1",
+			Arn:        "arn:aws:ecs:us-east-1:123456789012:service/web",
+			State:      "PRIMARY",
+			Count:      6,
+		}))
+}
+
+func TestResourceScanner_recordFiltered(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	report := &interfaces.ScanReport{}
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger, Report: report}
+
+	mockLogger.On("Log", []string{"filtered", "123456789012", "us-east-1", "AWS::EC2::Instance", "3", "", "", "", "EXCLUDE_TAGS env=sandbox", ""}).Return(nil).Once()
+	mockLogger.On("Log", []string{"filtered", "123456789012", "us-east-1", "AWS::EC2::Instance", "1", "", "", "", "INCLUDE_TAGS", ""}).Return(nil).Once()
+	mockLogger.On("Log", []string{"filtered", "123456789012", "us-east-1", "AWS::EC2::Volume", "2", "", "", "", "EXCLUDE_TAGS env=sandbox", ""}).Return(nil).Once()
+
+	scanner.recordFiltered("AWS::EC2::Instance", map[string]int{"INCLUDE_TAGS": 1, "EXCLUDE_TAGS env=sandbox": 3})
+	scanner.recordFiltered("AWS::EC2::Volume", map[string]int{"EXCLUDE_TAGS env=sandbox": 2})
+	scanner.recordFiltered("AWS::EC2::Volume", nil)
+
+	assert.Equal(t, map[string]int{"INCLUDE_TAGS": 1, "EXCLUDE_TAGS env=sandbox": 5}, report.Filtered)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestResourceScanner_recordUnfiltered(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	report := &interfaces.ScanReport{}
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger, Report: report}
+
+	mockLogger.On("Log", []string{"unfiltered", "123456789012", "us-east-1", "AWS::ECR::PublicRepository", "7", "", "", "", "", ""}).Return(nil).Twice()
+
+	scanner.recordUnfiltered("AWS::ECR::PublicRepository", 7)
+	scanner.recordUnfiltered("AWS::ECR::PublicRepository", 7)
+	scanner.recordUnfiltered("AWS::EC2::Instance", 0)
+
+	assert.Equal(t, map[string]int{"AWS::ECR::PublicRepository": 14}, report.Unfiltered)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestResourceScanner_updateTagTotals(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	totals := &interfaces.ResourceTotals{}
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger, Totals: totals}
+	scanner.UserConfig.GroupByTag = "cost-center"
+
+	mockLogger.On("Log", mock.Anything).Return(nil)
+
+	scanner.updateTagTotals(map[string]interfaces.CounterResult{
+		"AWS::EC2::Instance": {
+			CounterClass: "AWS::EC2::Instance",
+			Count:        4,
+			Resources: []interfaces.ResourceRecord{
+				{Identifier: "i-1", Tags: map[string]string{"cost-center": "finance"}},
+				{Identifier: "i-2", Tags: map[string]string{"cost-center": "finance"}},
+				{Identifier: "i-3", Tags: map[string]string{"env": "prod"}},
+			},
+		},
+		"AWS::EC2::Volume": {
+			CounterClass: "AWS::EC2::Volume",
+			Count:        1,
+			Resources:    []interfaces.ResourceRecord{{Identifier: "vol-1", Tags: map[string]string{"cost-center": "finance"}}},
+		},
+		"AWS::EFS::FileSystem": {
+			CounterClass: "AWS::EFS::FileSystem",
+			Count:        2,
+			Resources: []interfaces.ResourceRecord{
+				{Identifier: "fs-1", Tags: map[string]string{"cost-center": "finance"}},
+				{Identifier: "fs-2", Tags: map[string]string{"cost-center": "finance"}},
+			},
+		},
+		"AWS::ECS::Cluster": {
+			CounterClass: "AWS::ECS::Cluster",
+			Count:        5,
+			Resources: []interfaces.ResourceRecord{
+				{Identifier: "ecs-svc/1", Tags: map[string]string{"cost-center": "web"}, Count: 5},
+			},
+		},
+		"AWS::AutoScaling::AutoScalingGroup": {
+			CounterClass: "AWS::AutoScaling::AutoScalingGroup",
+			Count:        2,
+			Resources:    []interfaces.ResourceRecord{{Identifier: "i-9", Tags: map[string]string{"cost-center": "batch"}}},
+		},
+	})
+
+	assert.Equal(t, map[string]*interfaces.ResourceTotals{
+		"finance":                {VirtualMachines: 2, NonOsDisks: 3},
+		"web":                    {ServerlessContainers: 5},
+		interfaces.UntaggedGroup: {VirtualMachines: 2},
+	}, totals.ByTag)
+	mockLogger.AssertCalled(t, "Log", []string{"group", "123456789012", "us-east-1", "", "3", "", "", "", "cost-center=finance", "Non-OS Disks"})
+	mockLogger.AssertCalled(t, "Log", []string{"group", "123456789012", "us-east-1", "", "2", "", "", "", "cost-center=finance", "Virtual Machines"})
+	mockLogger.AssertCalled(t, "Log", []string{"group", "123456789012", "us-east-1", "", "2", "", "", "", "cost-center=untagged", "Virtual Machines"})
+	mockLogger.AssertCalled(t, "Log", []string{"group", "123456789012", "us-east-1", "", "5", "", "", "", "cost-center=web", "Serverless Containers"})
+	mockLogger.AssertNumberOfCalls(t, "Log", 4)
+}
+
+func TestResourceScanner_logResults(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockCapacity := new(mocks.MockLogger)
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger, Capacity: mockCapacity}
+
+	mockLogger.On("Log", []string{"count", "123456789012", "us-east-1", "AWS::EC2::Instance", "4", "native", "", "", "", ""}).Return(nil).Once()
+	mockCapacity.On("Log", []string{"123456789012", "us-east-1", "web", "1", "10", "2", "2", "", ""}).Return(nil).Once()
+
+	scanner.logResults(map[string]interfaces.CounterResult{
+		"AWS::EC2::Instance": {CounterClass: "AWS::EC2::Instance", Count: 4, Source: "native"},
+		"AWS::AutoScaling::AutoScalingGroup": {
+			CounterClass: "AWS::AutoScaling::AutoScalingGroup",
+			Count:        2,
+			Source:       "native",
+			Details:      [][]string{{"web", "1", "10", "2", "2", "", ""}},
+		},
+	})
+
+	mockLogger.AssertExpectations(t)
+	mockLogger.AssertNumberOfCalls(t, "Log", 1)
+	mockCapacity.AssertExpectations(t)
+}
+
+func TestResourceScanner_logResultsWithLookback(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	scanner := &ResourceScanner{AccountId: "123456789012", Region: "us-east-1", Logger: mockLogger}
+	scanner.UserConfig.Lookback = time.Hour
+
+	mockLogger.On("Log", []string{"peak-adjustment", "123456789012", "us-east-1", "AWS::AutoScaling::AutoScalingGroup", "", "native", "4", "", "", ""}).Return(nil).Once()
+
+	scanner.logResults(map[string]interfaces.CounterResult{
+		"AWS::AutoScaling::AutoScalingGroup": {CounterClass: "AWS::AutoScaling::AutoScalingGroup", Count: 2, Peak: 6, Source: "native"},
+	})
+
+	mockLogger.AssertExpectations(t)
+}
@@ -7,9 +7,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type ScanResult struct {
@@ -17,6 +19,14 @@ type ScanResult struct {
 	Credentials aws.Credentials
 	UserConfig  config.Config
 	OrgAccounts []types.Account
+	// Report holds the findings of the scan, such as reconciliation discrepancies.
+	Report *interfaces.ScanReport
+	// Totals holds the resource totals of the scanned accounts.
+	Totals interfaces.ResourceTotals
+	// AccountTotals holds the totals of every account by account id.
+	AccountTotals map[string]*interfaces.ResourceTotals
+	// OUTotals holds the totals of every organizational unit by path.
+	OUTotals map[string]*interfaces.ResourceTotals
 }
 
 type Scanner struct {
@@ -27,6 +37,27 @@ type Scanner struct {
 	OrgDetector        interfaces.OrgDetector
 	OrgClientFactory   func(cfg aws.Config) interfaces.OrganizationsClient
 	Logger             interfaces.Logger
+	// ConfigClientFactory creates the AWS Config client used by ScanConfigAggregator.
+	ConfigClientFactory func(cfg aws.Config) interfaces.ConfigServiceClient
+	// ExplorerClientFactory creates the Resource Explorer client used when the
+	// configured source is a Resource Explorer aggregator index.
+	ExplorerClientFactory func(cfg aws.Config) interfaces.ResourceExplorerClient
+	// InventoryLogger receives a record of every counted resource when set.
+	InventoryLogger interfaces.Logger
+	// CapacityLogger receives the capacity of every Auto Scaling group when set.
+	CapacityLogger interfaces.Logger
+	// ScannedAccounts maps the accounts scanned by earlier targets to the name
+	// of their target. When set, those accounts are not scanned again.
+	ScannedAccounts map[string]string
+	// IAMClientFactory creates the IAM client used to simulate the policies of
+	// the scanning role when SIMULATE_POLICIES is set.
+	IAMClientFactory func(cfg aws.Config) interfaces.IAMClient
+	// Retries is the retry configuration of the clients created in every
+	// account and region.
+	Retries *utils.Retries
+	// Partition is the partition of the caller identity, used in the ARNs of
+	// the roles whose policies are simulated.
+	Partition string
 }
 
 func NewScanner(
@@ -56,63 +87,154 @@ func (s *Scanner) initializeScan(ctx context.Context, config config.Config) (aws
 		return aws.Config{}, aws.Credentials{}, nil, fmt.Errorf("failed to initialize AWS session")
 	}
 
-	regions, err := s.RegionsManager.GetRegions(ctx, cfg, config.Region, s.Logger)
+	var regions []string
+	var err error
+	if len(config.Regions) > 0 {
+		regions, err = s.knownRegions(ctx, cfg, config.Regions)
+	} else {
+		regions, err = s.RegionsManager.GetRegions(ctx, cfg, config.Region, s.Logger)
+	}
 	if err != nil {
 		log.Printf("Failed to get regions: %v", err)
 		return aws.Config{}, aws.Credentials{}, nil, fmt.Errorf("failed to get regions: %w", err)
 	}
+	if len(config.ExcludeRegions) > 0 {
+		regions = excludeRegions(regions, config.ExcludeRegions)
+	}
 
 	return cfg, initialCredentials, regions, nil
 }
 
-func (s *Scanner) initializeOrgScanner(cfg aws.Config, orgAccounts []types.Account, regions []string) *OrgScanner {
+func (s *Scanner) initializeOrgScanner(cfg aws.Config, orgAccounts []types.Account, regions []string, config config.Config, countSource interfaces.CountSource, report *interfaces.ScanReport) *OrgScanner {
 	orgClient := s.OrgClientFactory(cfg)
 	if orgClient == nil {
 		log.Printf("OrgClient is nil")
 		return nil
 	}
+	callerAccountId := ""
+	if !config.ForceAssumeRole {
+		callerAccountId = s.callerAccountId()
+	}
+	var sourceCovers func(accountId, region string, global bool) bool
+	if countSource != nil {
+		sourceCovers = func(accountId, region string, global bool) bool {
+			scanner := &ResourceScanner{AccountId: accountId, Region: region, Global: global, UserConfig: config, CountSource: countSource}
+			return scanner.sourceCovers()
+		}
+	}
 	return &OrgScanner{
+		CallerAccountId:    callerAccountId,
+		SourceCovers:       sourceCovers,
 		CredentialsManager: s.CredentialsManager,
 		OrgAccounts:        orgAccounts,
 		Logger:             s.Logger,
 		Regions:            regions,
 		STSClient:          s.STSClient,
 		OrgClient:          orgClient,
-		ScannerFactory: func(accountId, region string, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
+		RegionsManager:     s.RegionsManager,
+		AllRegions:         config.Region == "" && len(config.Regions) == 0,
+		ExcludedRegions:    config.ExcludeRegions,
+		Retries:            s.Retries,
+		Profile:            config.Profile,
+		Report:             report,
+		ScannerFactory: func(accountId, region string, global bool, credentials aws.Credentials, logger interfaces.Logger, totals *interfaces.ResourceTotals) ResourceScannerInterface {
 			return &ResourceScanner{
 				AccountId:   accountId,
 				Region:      region,
+				Global:      global,
 				Credentials: credentials,
 				Logger:      logger,
 				Totals:      totals,
+				UserConfig:  config,
+				CountSource: countSource,
+				Report:      report,
+				Inventory:   s.InventoryLogger,
+				Capacity:    s.CapacityLogger,
+				Retries:     s.Retries,
 			}
 		},
 	}
 }
 
-func (s *Scanner) performScan(cfg aws.Config, initialCredentials aws.Credentials, regions []string, orgAccounts []types.Account, config config.Config) (ScanResult, error) {
-	orgScanner := s.initializeOrgScanner(cfg, orgAccounts, regions)
+// callerAccountId returns the account of the caller credentials, or an empty
+// string when it cannot be determined.
+func (s *Scanner) callerAccountId() string {
+	identity, err := s.STSClient.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		s.Logger.Logf("Failed to get the caller identity: %v", err)
+		return ""
+	}
+	return aws.ToString(identity.Account)
+}
+
+func (s *Scanner) performScan(cfg aws.Config, initialCredentials aws.Credentials, regions []string, orgAccounts []types.Account, config config.Config, report *interfaces.ScanReport) (ScanResult, error) {
+	orgAccounts = s.unscannedAccounts(orgAccounts, config, report)
+	countSource := s.countSource(cfg, orgAccounts, regions, config)
+	orgScanner := s.initializeOrgScanner(cfg, orgAccounts, regions, config, countSource, report)
 	if orgScanner == nil {
 		log.Printf("Failed to initialize org scanner")
 		return ScanResult{}, fmt.Errorf("failed to initialize org scanner")
 	}
 
+	if config.SimulatePolicies && s.IAMClientFactory != nil {
+		s.simulatePolicies(cfg, orgAccounts, config, report)
+	}
 	orgScanner.Call()
+	s.recordScanned(orgScanner.Scanned, config)
+	for _, account := range orgAccounts {
+		if role := s.CredentialsManager.RoleFor(*account.Id); role != "" {
+			if report.AccountRoles == nil {
+				report.AccountRoles = map[string]string{}
+			}
+			report.AccountRoles[*account.Id] = role
+		}
+	}
 	return ScanResult{
 		Config:      cfg,
 		Credentials: initialCredentials,
 		UserConfig:  config,
 		OrgAccounts: orgAccounts,
+		Report:      report,
+		Totals:      orgScanner.Totals,
+
+		AccountTotals: orgScanner.AccountTotals,
+		OUTotals:      orgScanner.OUTotals,
 	}, nil
 }
 
+// countSource loads the Resource Explorer counts when it is the configured source.
+// When they cannot be loaded, every resource is counted by the normal counters.
+func (s *Scanner) countSource(cfg aws.Config, orgAccounts []types.Account, regions []string, userConfig config.Config) interfaces.CountSource {
+	if userConfig.Source != config.SourceResourceExplorer {
+		return nil
+	}
+
+	source := NewResourceExplorerSource(s.ExplorerClientFactory(cfg), userConfig.ExplorerViewArn, orgAccounts, regions)
+	if err := source.Load(context.TODO()); err != nil {
+		log.Printf("Falling back to the normal counters: %v", err)
+		s.Logger.Logf("Failed to load Resource Explorer counts: %v", err)
+		return nil
+	}
+	return source
+}
+
 func (s *Scanner) ScanSingleAccount(ctx context.Context, config config.Config) (ScanResult, error) {
 	cfg, initialCredentials, regions, err := s.initializeScan(ctx, config)
 	if err != nil {
 		return ScanResult{}, err
 	}
 	orgAccounts := []types.Account{{Id: aws.String(config.AccountId)}}
-	return s.performScan(cfg, initialCredentials, regions, orgAccounts, config)
+	return s.performScan(cfg, initialCredentials, regions, orgAccounts, config, &interfaces.ScanReport{})
+}
+
+// ScanAccounts scans the accounts listed in the configuration, whether or not
+// they belong to the organization of the caller.
+func (s *Scanner) ScanAccounts(ctx context.Context, config config.Config) (ScanResult, error) {
+	cfg, initialCredentials, regions, err := s.initializeScan(ctx, config)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	return s.performScan(cfg, initialCredentials, regions, listedAccounts(config.IncludeAccounts), config, &interfaces.ScanReport{})
 }
 
 func (s *Scanner) ScanOrganization(ctx context.Context, config config.Config) (ScanResult, error) {
@@ -121,15 +243,227 @@ func (s *Scanner) ScanOrganization(ctx context.Context, config config.Config) (S
 		return ScanResult{}, err
 	}
 
+	report := &interfaces.ScanReport{}
+	orgAccounts, err := s.organizationAccounts(cfg, config, report)
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	return s.performScan(cfg, initialCredentials, regions, orgAccounts, config, report)
+}
+
+// ScanConfigAggregator counts the resources of the selected accounts with the
+// AWS Config aggregator named in the configuration instead of assuming roles.
+func (s *Scanner) ScanConfigAggregator(ctx context.Context, config config.Config) (ScanResult, error) {
+	cfg, initialCredentials, regions, err := s.initializeScan(ctx, config)
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	report := &interfaces.ScanReport{}
+	orgAccounts := []types.Account{{Id: aws.String(config.AccountId)}}
+	if len(config.IncludeAccounts) > 0 {
+		orgAccounts = listedAccounts(config.IncludeAccounts)
+	} else if config.AccountId == "" {
+		orgAccounts, err = s.organizationAccounts(cfg, config, report)
+		if err != nil {
+			return ScanResult{}, err
+		}
+	}
+
+	orgAccounts = s.unscannedAccounts(orgAccounts, config, report)
+	aggregatorScanner := &AggregatorScanner{
+		Client:         s.ConfigClientFactory(cfg),
+		AggregatorName: config.AggregatorName,
+		OrgAccounts:    orgAccounts,
+		Regions:        regions,
+		Logger:         s.Logger,
+		OrgClient:      s.OrgClientFactory(cfg),
+	}
+	if err := aggregatorScanner.Call(); err != nil {
+		return ScanResult{}, err
+	}
+	s.recordScanned(aggregatorScanner.Scanned, config)
+
+	return ScanResult{
+		Config:      cfg,
+		Credentials: initialCredentials,
+		UserConfig:  config,
+		OrgAccounts: orgAccounts,
+		Report:      report,
+		Totals:      aggregatorScanner.Totals,
+
+		AccountTotals: aggregatorScanner.AccountTotals,
+		OUTotals:      aggregatorScanner.OUTotals,
+	}, nil
+}
+
+// organizationAccounts lists the active accounts of the organization that are
+// not excluded, and records the skipped accounts in the report.
+func (s *Scanner) organizationAccounts(cfg aws.Config, config config.Config, report *interfaces.ScanReport) ([]types.Account, error) {
 	allAccounts := s.OrgDetector.ListAccounts()
 	orgClient := s.OrgClientFactory(cfg)
 	if orgClient == nil {
 		log.Printf("orgClient is nil")
-		return ScanResult{}, fmt.Errorf("orgClient is nil")
+		return nil, fmt.Errorf("orgClient is nil")
+	}
+
+	allAccounts, err := s.selectOrganizationalUnits(orgClient, allAccounts, config, report)
+	if err != nil {
+		return nil, err
 	}
+	allAccounts = s.selectAccountNames(allAccounts, config, report)
 
 	accountFilter := utils.NewAccountFilter(allAccounts, orgClient, s.Logger, config.ExcludeAccounts)
-	orgAccounts := accountFilter.FilterActiveAccounts()
+	activeAccounts := accountFilter.FilterActiveAccounts()
+	report.SkippedAccounts = append(report.SkippedAccounts, accountFilter.SkippedAccounts()...)
+	return activeAccounts, nil
+}
 
-	return s.performScan(cfg, initialCredentials, regions, orgAccounts, config)
+// knownRegions returns the selected regions when the partition knows all of
+// them, whether or not they are enabled in the caller account: the regions
+// enabled in each account are selected when it is scanned. The selected
+// regions are returned unchecked when the regions cannot be described.
+func (s *Scanner) knownRegions(ctx context.Context, cfg aws.Config, selected []string) ([]string, error) {
+	statuses, err := s.RegionsManager.AccountRegions(ctx, cfg)
+	if err != nil {
+		s.Logger.Logf("Failed to describe the regions of the partition, scanning the selected regions: %v", err)
+		return selected, nil
+	}
+	unknown := []string{}
+	for _, region := range selected {
+		if _, ok := statuses[region]; !ok {
+			unknown = append(unknown, region)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown regions: %s", strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+// excludeRegions leaves out the regions that are in the excluded list.
+func excludeRegions(regions, excluded []string) []string {
+	skip := toSet(excluded)
+	kept := []string{}
+	for _, region := range regions {
+		if !skip[region] {
+			kept = append(kept, region)
+		}
+	}
+	return kept
+}
+
+// unscannedAccounts leaves out the accounts scanned by an earlier target.
+func (s *Scanner) unscannedAccounts(accounts []types.Account, config config.Config, report *interfaces.ScanReport) []types.Account {
+	if s.ScannedAccounts == nil {
+		return accounts
+	}
+	kept := []types.Account{}
+	for _, account := range accounts {
+		accountId := aws.ToString(account.Id)
+		if target, ok := s.ScannedAccounts[accountId]; ok {
+			s.Logger.Logf("Skipping account already scanned in target %s: %s", target, accountId)
+			report.SkipAccount(accountId, interfaces.SkipReasonDuplicate, "scanned in target "+target)
+			continue
+		}
+		kept = append(kept, account)
+	}
+	return kept
+}
+
+// recordScanned records the accounts as scanned by the target of the
+// configuration, so that later targets do not scan them again. Accounts that
+// could not be scanned are left to the later targets.
+func (s *Scanner) recordScanned(accountIds []string, config config.Config) {
+	if s.ScannedAccounts == nil {
+		return
+	}
+	for _, accountId := range accountIds {
+		s.ScannedAccounts[accountId] = config.Target
+	}
+}
+
+// listedAccounts returns the accounts with the given IDs.
+func listedAccounts(accountIds []string) []types.Account {
+	accounts := make([]types.Account, 0, len(accountIds))
+	for _, accountId := range accountIds {
+		accounts = append(accounts, types.Account{Id: aws.String(accountId)})
+	}
+	return accounts
+}
+
+// selectAccountNames keeps the accounts whose name or email matches any of the
+// included patterns, when there are any, and none of the excluded patterns.
+func (s *Scanner) selectAccountNames(accounts []types.Account, config config.Config, report *interfaces.ScanReport) []types.Account {
+	if len(config.IncludeNames) == 0 && len(config.ExcludeNames) == 0 {
+		return accounts
+	}
+
+	selected := []types.Account{}
+	for _, account := range accounts {
+		name, email := aws.ToString(account.Name), aws.ToString(account.Email)
+		included := len(config.IncludeNames) == 0
+		for _, pattern := range config.IncludeNames {
+			if pattern.Matches(name, email) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			s.Logger.Logf("Skipping account not matching the included names: %s", *account.Id)
+			report.SkipAccount(*account.Id, interfaces.SkipReasonExcluded, "INCLUDE_NAMES")
+			continue
+		}
+		excluded := false
+		for _, pattern := range config.ExcludeNames {
+			if pattern.Matches(name, email) {
+				s.Logger.Logf("Skipping account matching excluded name %s: %s", pattern, *account.Id)
+				report.SkipAccount(*account.Id, interfaces.SkipReasonExcluded, "EXCLUDE_NAMES "+pattern.String())
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			selected = append(selected, account)
+		}
+	}
+	return selected
+}
+
+// selectOrganizationalUnits keeps the accounts in any of the included
+// organizational units, when there are any, that are not in an excluded one.
+func (s *Scanner) selectOrganizationalUnits(orgClient interfaces.OrganizationsClient, accounts []types.Account, config config.Config, report *interfaces.ScanReport) ([]types.Account, error) {
+	if len(config.IncludeOUs) == 0 && len(config.ExcludeOUs) == 0 {
+		return accounts, nil
+	}
+
+	units := &orgUnitAccounts{Client: orgClient}
+	var included map[string]bool
+	if len(config.IncludeOUs) > 0 {
+		ids, err := units.AccountIds(context.TODO(), config.IncludeOUs)
+		if err != nil {
+			return nil, err
+		}
+		included = ids
+	}
+	excluded, err := units.AccountIds(context.TODO(), config.ExcludeOUs)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := []types.Account{}
+	for _, account := range accounts {
+		switch {
+		case included != nil && !included[*account.Id]:
+			s.Logger.Logf("Skipping account outside the included organizational units: %s", *account.Id)
+			report.SkipAccount(*account.Id, interfaces.SkipReasonExcluded, "INCLUDE_OUS")
+		case excluded[*account.Id]:
+			s.Logger.Logf("Skipping account in an excluded organizational unit: %s", *account.Id)
+			report.SkipAccount(*account.Id, interfaces.SkipReasonExcluded, "EXCLUDE_OUS")
+		default:
+			selected = append(selected, account)
+		}
+	}
+	return selected, nil
 }
@@ -4,12 +4,16 @@ import (
 	"aws-resource-discovery/pkg/config"
 	"aws-resource-discovery/pkg/interfaces"
 	"aws-resource-discovery/pkg/mocks"
+	"aws-resource-discovery/pkg/utils"
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -29,8 +33,12 @@ func TestScanner_ScanSingleAccount(t *testing.T) {
 
 	mockSessionManager.On("InitializeSessionAndCredentials", mock.Anything, mock.Anything, mock.Anything).Return(aws.Config{Region: "us-east-1"}, aws.Credentials{})
 	mockRegionsManager.On("GetRegions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]string{"us-east-1", "us-west-2"}, nil)
+	mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(map[string]string{"us-east-1": interfaces.RegionEnabled, "us-west-2": interfaces.RegionEnabled}, nil)
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(&sts.GetCallerIdentityOutput{Account: aws.String("999999999999")}, nil)
 	mockCredentialsManager.On("CredentialsFor", mock.Anything, "123456789012", "us-east-1").Return(aws.Credentials{}, nil)
 	mockCredentialsManager.On("CredentialsFor", mock.Anything, "123456789012", "us-west-2").Return(aws.Credentials{}, nil)
+	mockCredentialsManager.On("RoleFor", "123456789012").Return("red-canary-resource-discovery-role")
+	mockOrgClient.On("ListParents", mock.Anything, mock.Anything).Return(nil, errors.New("AWSOrganizationsNotInUseException"))
 	mockLogger.On("Log", mock.Anything).Return(nil)
 	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
@@ -69,12 +77,18 @@ func TestScanner_ScanOrganization(t *testing.T) {
 
 	mockSessionManager.On("InitializeSessionAndCredentials", mock.Anything, mock.Anything, mock.Anything).Return(aws.Config{Region: "us-east-1"}, aws.Credentials{})
 	mockRegionsManager.On("GetRegions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]string{"us-east-1", "us-west-2"}, nil)
+	mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(map[string]string{"us-east-1": interfaces.RegionEnabled, "us-west-2": interfaces.RegionEnabled}, nil)
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(&sts.GetCallerIdentityOutput{Account: aws.String("999999999999")}, nil)
 	mockOrgDetector.On("ListAccounts").Return([]types.Account{{Id: aws.String("123456789012")}})
 	mockCredentialsManager.On("CredentialsFor", mock.Anything, "123456789012", "us-east-1").Return(aws.Credentials{}, nil)
 	mockCredentialsManager.On("CredentialsFor", mock.Anything, "123456789012", "us-west-2").Return(aws.Credentials{}, nil)
+	mockCredentialsManager.On("RoleFor", "123456789012").Return("red-canary-resource-discovery-role")
 	mockOrgClient.On("DescribeAccount", mock.Anything, mock.Anything).Return(&organizations.DescribeAccountOutput{
 		Account: &types.Account{Id: aws.String("123456789012"), Status: types.AccountStatusActive},
 	}, nil)
+	mockOrgClient.On("ListParents", mock.Anything, mock.Anything).Return(&organizations.ListParentsOutput{
+		Parents: []types.Parent{{Id: aws.String("r-root"), Type: types.ParentTypeRoot}},
+	}, nil)
 	mockLogger.On("Log", mock.Anything).Return(nil)
 	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
@@ -94,8 +108,220 @@ func TestScanner_ScanOrganization(t *testing.T) {
 	mockSessionManager.AssertCalled(t, "InitializeSessionAndCredentials", ctx, userConfig, mockLogger)
 	mockRegionsManager.AssertCalled(t, "GetRegions", ctx, mock.Anything, "us-east-1", mockLogger)
 	mockOrgDetector.AssertCalled(t, "ListAccounts")
+	assert.Equal(t, map[string]string{"123456789012": "red-canary-resource-discovery-role"}, result.Report.AccountRoles)
 	mockCredentialsManager.AssertCalled(t, "CredentialsFor", mock.Anything, "123456789012", "us-east-1")
 	mockCredentialsManager.AssertCalled(t, "CredentialsFor", mock.Anything, "123456789012", "us-west-2")
 	mockOrgClient.AssertCalled(t, "DescribeAccount", mock.Anything, mock.Anything)
+	mockOrgClient.AssertCalled(t, "ListParents", mock.Anything, mock.Anything)
 	mockLogger.AssertCalled(t, "Log", mock.Anything)
 }
+
+func TestScanner_ScanConfigAggregator(t *testing.T) {
+	mockSTSClient := new(mocks.MockSTSClient)
+	mockSessionManager := new(mocks.MockSessionManager)
+	mockRegionsManager := new(mocks.MockRegionsManager)
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockOrgDetector := new(mocks.MockOrgDetector)
+	mockLogger := new(mocks.MockLogger)
+	mockOrgClient := new(mocks.MockOrganizationsClient)
+	mockConfigClient := new(mocks.MockConfigServiceClient)
+
+	mockOrgClientFactory := func(cfg aws.Config) interfaces.OrganizationsClient {
+		return mockOrgClient
+	}
+
+	mockSessionManager.On("InitializeSessionAndCredentials", mock.Anything, mock.Anything, mock.Anything).Return(aws.Config{Region: "us-east-1"}, aws.Credentials{})
+	mockRegionsManager.On("GetRegions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]string{"us-east-1"}, nil)
+	mockConfigClient.On("DescribeConfigurationAggregatorSourcesStatus", mock.Anything, mock.Anything).Return(&configservice.DescribeConfigurationAggregatorSourcesStatusOutput{}, nil)
+	mockConfigClient.On("SelectAggregateResourceConfig", mock.Anything, mock.Anything).Return(&configservice.SelectAggregateResourceConfigOutput{}, nil)
+	mockOrgClient.On("ListParents", mock.Anything, mock.Anything).Return(&organizations.ListParentsOutput{
+		Parents: []types.Parent{{Id: aws.String("r-root"), Type: types.ParentTypeRoot}},
+	}, nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	scanner := NewScanner(mockSTSClient, mockSessionManager, mockRegionsManager, mockCredentialsManager, mockOrgDetector, mockOrgClientFactory, mockLogger)
+	scanner.ConfigClientFactory = func(cfg aws.Config) interfaces.ConfigServiceClient {
+		return mockConfigClient
+	}
+
+	userConfig := config.Config{AccountId: "123456789012", Region: "us-east-1", Source: config.SourceConfigAggregator, AggregatorName: "org-aggregator"}
+
+	result, err := scanner.ScanConfigAggregator(context.Background(), userConfig)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.OrgAccounts, 1)
+	mockConfigClient.AssertCalled(t, "SelectAggregateResourceConfig", mock.Anything, mock.Anything)
+	mockCredentialsManager.AssertNotCalled(t, "CredentialsFor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestScanner_selectOrganizationalUnits(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockOrgClient := new(mocks.MockOrganizationsClient)
+	mockOrganizationTree(mockOrgClient)
+	mockLogger.On("Logf", mock.Anything, mock.Anything).Return(nil)
+
+	scanner := &Scanner{Logger: mockLogger}
+	accounts := []types.Account{{Id: aws.String("account1")}, {Id: aws.String("account2")}, {Id: aws.String("account3")}}
+
+	report := &interfaces.ScanReport{}
+	selected, err := scanner.selectOrganizationalUnits(mockOrgClient, accounts, config.Config{
+		IncludeOUs: []string{"Root/Workloads"},
+		ExcludeOUs: []string{"Root/Workloads/Sandbox"},
+	}, report)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Account{{Id: aws.String("account1")}}, selected)
+	assert.Equal(t, []interfaces.SkippedAccount{
+		{AccountId: "account2", Reason: interfaces.SkipReasonExcluded, Detail: "EXCLUDE_OUS"},
+		{AccountId: "account3", Reason: interfaces.SkipReasonExcluded, Detail: "INCLUDE_OUS"},
+	}, report.SkippedAccounts)
+	mockLogger.AssertCalled(t, "Logf", "Skipping account in an excluded organizational unit: %s", "account2")
+	mockLogger.AssertCalled(t, "Logf", "Skipping account outside the included organizational units: %s", "account3")
+
+	selected, err = scanner.selectOrganizationalUnits(mockOrgClient, accounts, config.Config{}, &interfaces.ScanReport{})
+	assert.NoError(t, err)
+	assert.Equal(t, accounts, selected)
+}
+
+func TestScanner_ScanAccounts(t *testing.T) {
+	mockSTSClient := new(mocks.MockSTSClient)
+	mockSessionManager := new(mocks.MockSessionManager)
+	mockRegionsManager := new(mocks.MockRegionsManager)
+	mockCredentialsManager := new(mocks.MockCredentialsManager)
+	mockOrgDetector := new(mocks.MockOrgDetector)
+	mockLogger := new(mocks.MockLogger)
+	mockOrgClient := new(mocks.MockOrganizationsClient)
+
+	mockSessionManager.On("InitializeSessionAndCredentials", mock.Anything, mock.Anything, mock.Anything).Return(aws.Config{Region: "us-east-1"}, aws.Credentials{})
+	mockRegionsManager.On("GetRegions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]string{"us-east-1"}, nil)
+	mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(map[string]string{"us-east-1": interfaces.RegionEnabled, "us-west-2": interfaces.RegionEnabled}, nil)
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
+	mockCredentialsManager.On("CredentialsFor", mock.Anything, mock.Anything, "us-east-1").Return(aws.Credentials{}, errors.New("test error"))
+	mockCredentialsManager.On("RoleFor", mock.Anything).Return("")
+	mockOrgClient.On("ListParents", mock.Anything, mock.Anything).Return(nil, errors.New("AWSOrganizationsNotInUseException"))
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	scanner := NewScanner(mockSTSClient, mockSessionManager, mockRegionsManager, mockCredentialsManager, mockOrgDetector, func(cfg aws.Config) interfaces.OrganizationsClient {
+		return mockOrgClient
+	}, mockLogger)
+
+	userConfig := config.Config{IncludeAccounts: []string{"123456789012", "210987654321"}}
+	result, err := scanner.ScanAccounts(context.Background(), userConfig)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.OrgAccounts, 2)
+	assert.Equal(t, "210987654321", *result.OrgAccounts[1].Id)
+	mockOrgDetector.AssertNotCalled(t, "ListAccounts")
+	assert.Nil(t, result.Report.AccountRoles)
+	mockCredentialsManager.AssertCalled(t, "CredentialsFor", mock.Anything, "123456789012", "us-east-1")
+	mockCredentialsManager.AssertCalled(t, "CredentialsFor", mock.Anything, "210987654321", "us-east-1")
+}
+
+func TestScanner_selectAccountNames(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Logf", mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	include, _ := utils.ParseAccountPatterns("prod-*")
+	exclude, _ := utils.ParseAccountPatterns("/sandbox@/")
+
+	scanner := &Scanner{Logger: mockLogger}
+	accounts := []types.Account{
+		{Id: aws.String("account1"), Name: aws.String("prod-payments"), Email: aws.String("payments@example.com")},
+		{Id: aws.String("account2"), Name: aws.String("prod-lab"), Email: aws.String("sandbox@example.com")},
+		{Id: aws.String("account3"), Name: aws.String("staging"), Email: aws.String("staging@example.com")},
+	}
+
+	report := &interfaces.ScanReport{}
+	selected := scanner.selectAccountNames(accounts, config.Config{IncludeNames: include, ExcludeNames: exclude}, report)
+
+	assert.Equal(t, accounts[:1], selected)
+	assert.Equal(t, []interfaces.SkippedAccount{
+		{AccountId: "account2", Reason: interfaces.SkipReasonExcluded, Detail: "EXCLUDE_NAMES /sandbox@/"},
+		{AccountId: "account3", Reason: interfaces.SkipReasonExcluded, Detail: "INCLUDE_NAMES"},
+	}, report.SkippedAccounts)
+	mockLogger.AssertCalled(t, "Logf", "Skipping account matching excluded name %s: %s", exclude[0], "account2")
+	mockLogger.AssertCalled(t, "Logf", "Skipping account not matching the included names: %s", "account3")
+}
+
+func TestScanner_unscannedAccounts(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	scanner := &Scanner{Logger: mockLogger, ScannedAccounts: map[string]string{"account1": "prod"}}
+	accounts := []types.Account{{Id: aws.String("account1")}, {Id: aws.String("account2")}}
+
+	report := &interfaces.ScanReport{}
+	selected := scanner.unscannedAccounts(accounts, config.Config{Target: "acquisitions"}, report)
+
+	assert.Equal(t, accounts[1:], selected)
+	assert.Equal(t, map[string]string{"account1": "prod"}, scanner.ScannedAccounts)
+	assert.Equal(t, []interfaces.SkippedAccount{
+		{AccountId: "account1", Reason: interfaces.SkipReasonDuplicate, Detail: "scanned in target prod"},
+	}, report.SkippedAccounts)
+	mockLogger.AssertCalled(t, "Logf", "Skipping account already scanned in target %s: %s", "prod", "account1")
+
+	// Without earlier targets every account is scanned
+	assert.Equal(t, accounts, (&Scanner{}).unscannedAccounts(accounts, config.Config{}, report))
+}
+
+func TestScanner_recordScanned(t *testing.T) {
+	scanner := &Scanner{ScannedAccounts: map[string]string{"account1": "prod"}}
+
+	scanner.recordScanned([]string{"account2"}, config.Config{Target: "acquisitions"})
+	assert.Equal(t, map[string]string{"account1": "prod", "account2": "acquisitions"}, scanner.ScannedAccounts)
+
+	// Without earlier targets nothing is recorded
+	(&Scanner{}).recordScanned([]string{"account2"}, config.Config{})
+}
+
+func TestScanner_knownRegions(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockRegionsManager := new(mocks.MockRegionsManager)
+	mockLogger.On("Logf", mock.Anything, mock.Anything).Return(nil)
+	scanner := &Scanner{RegionsManager: mockRegionsManager, Logger: mockLogger}
+	ctx := context.TODO()
+
+	// Regions the caller account has not opted in to are kept
+	mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(map[string]string{
+		"us-east-1":  interfaces.RegionEnabled,
+		"me-south-1": interfaces.RegionDisabled,
+	}, nil).Once()
+	regions, err := scanner.knownRegions(ctx, aws.Config{}, []string{"me-south-1", "us-east-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"me-south-1", "us-east-1"}, regions)
+
+	// Misspelled regions are rejected
+	mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(map[string]string{
+		"us-east-1": interfaces.RegionEnabled,
+	}, nil).Once()
+	_, err = scanner.knownRegions(ctx, aws.Config{}, []string{"us-east1", "us-east-1"})
+	assert.EqualError(t, err, "unknown regions: us-east1")
+
+	// The selected regions are kept when the regions cannot be described
+	mockRegionsManager.On("AccountRegions", mock.Anything, mock.Anything).Return(nil, errors.New("access denied")).Once()
+	regions, err = scanner.knownRegions(ctx, aws.Config{}, []string{"us-east-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-east-1"}, regions)
+}
+
+func TestExcludeRegions(t *testing.T) {
+	assert.Equal(t, []string{"us-east-1"}, excludeRegions([]string{"us-east-1", "us-west-2", "eu-west-1"}, []string{"eu-west-1", "us-west-2"}))
+	assert.Equal(t, []string{"us-east-1"}, excludeRegions([]string{"us-east-1"}, nil))
+}
+
+func TestScanner_initializeOrgScannerCallerAccount(t *testing.T) {
+	mockSTSClient := new(mocks.MockSTSClient)
+	mockOrgClient := new(mocks.MockOrganizationsClient)
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(&sts.GetCallerIdentityOutput{Account: aws.String("123456789012")}, nil).Once()
+
+	scanner := &Scanner{STSClient: mockSTSClient, OrgClientFactory: func(cfg aws.Config) interfaces.OrganizationsClient {
+		return mockOrgClient
+	}}
+
+	orgScanner := scanner.initializeOrgScanner(aws.Config{}, nil, nil, config.Config{}, nil, &interfaces.ScanReport{})
+	assert.Equal(t, "123456789012", orgScanner.CallerAccountId)
+
+	orgScanner = scanner.initializeOrgScanner(aws.Config{}, nil, nil, config.Config{ForceAssumeRole: true}, nil, &interfaces.ScanReport{})
+	assert.Empty(t, orgScanner.CallerAccountId)
+	mockSTSClient.AssertNumberOfCalls(t, "GetCallerIdentity", 1)
+}
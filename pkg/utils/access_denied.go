@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+// accessDeniedCodes are the error codes AWS services return for a missing permission.
+var accessDeniedCodes = map[string]bool{
+	"AccessDenied":          true,
+	"AccessDeniedException": true,
+	"UnauthorizedOperation": true,
+	"UnauthorizedException": true,
+	"AuthorizationError":    true,
+}
+
+// IsAccessDenied reports whether an error is caused by a missing permission.
+func IsAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && accessDeniedCodes[apiErr.ErrorCode()]
+}
+
+// IsSCPDeny reports whether an error is an explicit deny of a service control
+// policy, such as the ones that restrict an organization to approved regions.
+func IsSCPDeny(err error) bool {
+	return IsAccessDenied(err) && strings.Contains(err.Error(), "explicit deny in a service control policy")
+}
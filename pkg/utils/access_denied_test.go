@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAccessDenied(t *testing.T) {
+	assert.True(t, IsAccessDenied(&smithy.GenericAPIError{Code: "UnauthorizedOperation"}))
+	assert.False(t, IsAccessDenied(&smithy.GenericAPIError{Code: "ThrottlingException"}))
+	assert.False(t, IsAccessDenied(errors.New("test error")))
+}
+
+func TestIsSCPDeny(t *testing.T) {
+	assert.True(t, IsSCPDeny(&smithy.GenericAPIError{Code: "AccessDeniedException", Message: "User: arn:aws:sts::111111111111:assumed-role/scanner/s is not authorized to perform: lambda:ListFunctions with an explicit deny in a service control policy"}))
+	assert.False(t, IsSCPDeny(&smithy.GenericAPIError{Code: "AccessDenied", Message: "User: arn:aws:sts::111111111111:assumed-role/scanner/s is not authorized to perform: ecs:ListClusters with an explicit deny"}))
+	assert.False(t, IsSCPDeny(&smithy.GenericAPIError{Code: "AccessDeniedException", Message: "User: arn:aws:sts::111111111111:assumed-role/scanner/s is not authorized to perform: lambda:ListFunctions with an explicit deny in an identity-based policy"}))
+	assert.False(t, IsSCPDeny(&smithy.GenericAPIError{Code: "AccessDeniedException", Message: "User: arn:aws:sts::111111111111:assumed-role/scanner/s is not authorized to perform: lambda:ListFunctions because no identity-based policy allows the lambda:ListFunctions action"}))
+	assert.False(t, IsSCPDeny(&smithy.GenericAPIError{Code: "ThrottlingException", Message: "explicit deny"}))
+	assert.False(t, IsSCPDeny(nil))
+}
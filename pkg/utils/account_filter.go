@@ -4,7 +4,6 @@ import (
 	"aws-resource-discovery/pkg/interfaces"
 	"context"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
 )
@@ -14,6 +13,7 @@ type accountFilter struct {
 	OrgClient       interfaces.OrganizationsClient
 	Logger          interfaces.Logger
 	ExcludeAccounts []string
+	skipped         []interfaces.SkippedAccount
 }
 
 func NewAccountFilter(orgAccounts []types.Account, orgClient interfaces.OrganizationsClient, logger interfaces.Logger, excludeAccounts []string) interfaces.AccountFilter {
@@ -25,34 +25,58 @@ func NewAccountFilter(orgAccounts []types.Account, orgClient interfaces.Organiza
 	}
 }
 
+// FilterActiveAccounts returns the active accounts that are not excluded. The
+// status listed with the accounts is used when present, so DescribeAccount is
+// only called for accounts listed without one.
 func (af *accountFilter) FilterActiveAccounts() []types.Account {
 	activeAccounts := []types.Account{}
+	af.skipped = nil
 	for _, account := range af.OrgAccounts {
 		if af.isExcludedAccount(*account.Id) {
 			af.Logger.Logf("Skipping excluded account: %s", *account.Id)
+			af.skip(*account.Id, interfaces.SkipReasonExcluded, "EXCLUDE")
 			continue
 		}
-		if af.isAccountActive(*account.Id) {
+		status, err := af.accountStatus(account)
+		if err != nil {
+			af.Logger.Logf("Failed to describe account %s: %v", *account.Id, err)
+			af.skip(*account.Id, interfaces.SkipReasonLookupFailed, err.Error())
+			continue
+		}
+		if status == types.AccountStatusActive {
 			activeAccounts = append(activeAccounts, account)
 		} else {
 			af.Logger.Logf("Skipping suspended account: %s", *account.Id)
+			af.skip(*account.Id, interfaces.SkipReasonSuspended, string(status))
 		}
 	}
 	return activeAccounts
 }
 
-func (af *accountFilter) isAccountActive(accountId string) bool {
-    input := &organizations.DescribeAccountInput{
-        AccountId: aws.String(accountId),
-    }
+// SkippedAccounts returns the accounts left out by the last FilterActiveAccounts call.
+func (af *accountFilter) SkippedAccounts() []interfaces.SkippedAccount {
+	return af.skipped
+}
+
+func (af *accountFilter) skip(accountId, reason, detail string) {
+	af.skipped = append(af.skipped, interfaces.SkippedAccount{AccountId: accountId, Reason: reason, Detail: detail})
+}
 
-    result, err := af.OrgClient.DescribeAccount(context.Background(), input)
-    if err != nil {
-        af.Logger.Logf("Failed to describe account %s: %v", accountId, err)
-        return false
-    }
+// accountStatus returns the listed status of an account, or describes the
+// account when it was listed without one.
+func (af *accountFilter) accountStatus(account types.Account) (types.AccountStatus, error) {
+	if account.Status != "" {
+		return account.Status, nil
+	}
 
-    return result.Account.Status == types.AccountStatusActive
+	input := &organizations.DescribeAccountInput{
+		AccountId: account.Id,
+	}
+	result, err := af.OrgClient.DescribeAccount(context.Background(), input)
+	if err != nil {
+		return "", err
+	}
+	return result.Account.Status, nil
 }
 
 func (af *accountFilter) isExcludedAccount(accountId string) bool {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"aws-resource-discovery/pkg/interfaces"
 	"aws-resource-discovery/pkg/mocks"
 	"errors"
 	"testing"
@@ -20,30 +21,38 @@ func TestFilterActiveAccounts(t *testing.T) {
 		{Id: aws.String("111111111111"), Status: types.AccountStatusActive},
 		{Id: aws.String("222222222222"), Status: types.AccountStatusSuspended},
 		{Id: aws.String("333333333333"), Status: types.AccountStatusActive},
+		{Id: aws.String("444444444444")},
+		{Id: aws.String("555555555555")},
 	}
 
 	excludeAccounts := []string{"333333333333"}
 
 	filter := NewAccountFilter(accounts, mockOrgClient, mockLogger, excludeAccounts)
 
+	// Only the accounts listed without a status are described
 	mockOrgClient.On("DescribeAccount", mock.Anything, &organizations.DescribeAccountInput{
-		AccountId: aws.String("111111111111"),
+		AccountId: aws.String("444444444444"),
 	}).Return(&organizations.DescribeAccountOutput{
 		Account: &types.Account{Status: types.AccountStatusActive},
 	}, nil)
 
 	mockOrgClient.On("DescribeAccount", mock.Anything, &organizations.DescribeAccountInput{
-		AccountId: aws.String("222222222222"),
-	}).Return(&organizations.DescribeAccountOutput{
-		Account: &types.Account{Status: types.AccountStatusSuspended},
-	}, nil)
+		AccountId: aws.String("555555555555"),
+	}).Return(nil, errors.New("test error"))
 
 	mockLogger.On("Logf", mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Logf", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	activeAccounts := filter.FilterActiveAccounts()
 
-	assert.Len(t, activeAccounts, 1)
+	assert.Len(t, activeAccounts, 2)
 	assert.Equal(t, "111111111111", *activeAccounts[0].Id)
+	assert.Equal(t, "444444444444", *activeAccounts[1].Id)
+	assert.Equal(t, []interfaces.SkippedAccount{
+		{AccountId: "222222222222", Reason: interfaces.SkipReasonSuspended, Detail: "SUSPENDED"},
+		{AccountId: "333333333333", Reason: interfaces.SkipReasonExcluded, Detail: "EXCLUDE"},
+		{AccountId: "555555555555", Reason: interfaces.SkipReasonLookupFailed, Detail: "test error"},
+	}, filter.SkippedAccounts())
 
 	mockOrgClient.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
@@ -59,21 +68,13 @@ func TestIsExcludedAccount(t *testing.T) {
 	assert.False(t, filter.isExcludedAccount("333333333333"))
 }
 
-func TestIsAccountActive(t *testing.T) {
+func TestAccountStatus(t *testing.T) {
 	mockOrgClient := new(mocks.MockOrganizationsClient)
-	mockLogger := new(mocks.MockLogger)
 
 	filter := &accountFilter{
 		OrgClient: mockOrgClient,
-		Logger:    mockLogger,
 	}
 
-	mockOrgClient.On("DescribeAccount", mock.Anything, &organizations.DescribeAccountInput{
-		AccountId: aws.String("111111111111"),
-	}).Return(&organizations.DescribeAccountOutput{
-		Account: &types.Account{Status: types.AccountStatusActive},
-	}, nil)
-
 	mockOrgClient.On("DescribeAccount", mock.Anything, &organizations.DescribeAccountInput{
 		AccountId: aws.String("222222222222"),
 	}).Return(&organizations.DescribeAccountOutput{
@@ -84,12 +85,16 @@ func TestIsAccountActive(t *testing.T) {
 		AccountId: aws.String("333333333333"),
 	}).Return(nil, errors.New("test error"))
 
-	mockLogger.On("Logf", "Failed to describe account %s: %v", "333333333333", mock.Anything).Return(nil)
+	status, err := filter.accountStatus(types.Account{Id: aws.String("111111111111"), Status: types.AccountStatusActive})
+	assert.NoError(t, err)
+	assert.Equal(t, types.AccountStatusActive, status)
 
-	assert.True(t, filter.isAccountActive("111111111111"))
-	assert.False(t, filter.isAccountActive("222222222222"))
-	assert.False(t, filter.isAccountActive("333333333333"))
+	status, err = filter.accountStatus(types.Account{Id: aws.String("222222222222")})
+	assert.NoError(t, err)
+	assert.Equal(t, types.AccountStatusSuspended, status)
 
-	mockOrgClient.AssertExpectations(t)
-	mockLogger.AssertExpectations(t)
+	_, err = filter.accountStatus(types.Account{Id: aws.String("333333333333")})
+	assert.Error(t, err)
+
+	mockOrgClient.AssertNumberOfCalls(t, "DescribeAccount", 2)
 }
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"aws-resource-discovery/pkg/config"
+)
+
+var accountIdPattern = regexp.MustCompile(`^\d+$`)
+
+// ParseAccountList parses a comma-separated list of account IDs, or reads them
+// from a file when the value is @ followed by its path. The file may separate
+// the IDs with commas or newlines, and lines starting with # are ignored.
+func ParseAccountList(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		lines := []string{}
+		for _, line := range strings.Split(string(content), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				lines = append(lines, line)
+			}
+		}
+		value = strings.Join(lines, ",")
+	}
+
+	accounts := []string{}
+	for _, account := range strings.Split(value, ",") {
+		account = strings.TrimSpace(account)
+		if account == "" {
+			continue
+		}
+		if !accountIdPattern.MatchString(account) {
+			return nil, fmt.Errorf("invalid account ID %q", account)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// ParseAccountPatterns parses a comma-separated list of account name and email
+// patterns. Patterns between slashes are regular expressions; any other pattern
+// is a case-insensitive glob where * matches any characters and ? a single one.
+func ParseAccountPatterns(value string) ([]config.AccountPattern, error) {
+	if value == "" {
+		return nil, nil
+	}
+	patterns := []config.AccountPattern{}
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return nil, fmt.Errorf("invalid account pattern %q", value)
+		}
+		expression := globExpression(pattern)
+		if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			expression = pattern[1 : len(pattern)-1]
+		}
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, config.AccountPattern{Pattern: pattern, Regexp: compiled})
+	}
+	return patterns, nil
+}
+
+// globExpression returns the anchored, case-insensitive regular expression of a glob.
+func globExpression(glob string) string {
+	expression := regexp.QuoteMeta(glob)
+	expression = strings.ReplaceAll(expression, `\*`, ".*")
+	expression = strings.ReplaceAll(expression, `\?`, ".")
+	return "(?i)^" + expression + "$"
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccountList(t *testing.T) {
+	accounts, err := ParseAccountList("123456789012, 210987654321")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"123456789012", "210987654321"}, accounts)
+
+	accounts, err = ParseAccountList("")
+	assert.NoError(t, err)
+	assert.Nil(t, accounts)
+
+	_, err = ParseAccountList("123456789012,prod")
+	assert.ErrorContains(t, err, `invalid account ID "prod"`)
+}
+
+func TestParseAccountListFromFile(t *testing.T) {
+	filename := "test_accounts.txt"
+	defer os.Remove(filename)
+	os.WriteFile(filename, []byte("# acquisition\n123456789012\n210987654321,111111111111\n\n"), 0600)
+
+	accounts, err := ParseAccountList("@" + filename)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"123456789012", "210987654321", "111111111111"}, accounts)
+
+	_, err = ParseAccountList("@missing.txt")
+	assert.Error(t, err)
+}
+
+func TestParseAccountPatterns(t *testing.T) {
+	patterns, err := ParseAccountPatterns("prod-*,/^sec-[0-9]+@example\\.com$/")
+	assert.NoError(t, err)
+	assert.Len(t, patterns, 2)
+	assert.Equal(t, "prod-*", patterns[0].String())
+	assert.True(t, patterns[0].Matches("Prod-Payments"))
+	assert.False(t, patterns[0].Matches("staging-prod-payments", "ops@example.com"))
+	assert.True(t, patterns[1].Matches("Security", "sec-01@example.com"))
+	assert.False(t, patterns[1].Matches("Sec-01@example.com"))
+
+	patterns, err = ParseAccountPatterns("")
+	assert.NoError(t, err)
+	assert.Nil(t, patterns)
+
+	_, err = ParseAccountPatterns("/[/")
+	assert.Error(t, err)
+}
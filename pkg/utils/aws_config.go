@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+)
+
+// LoadAWSConfig loads the AWS SDK configuration of a named profile, or the
+// default configuration when the profile is empty. An empty region keeps the
+// region of the profile. The clients of the configuration retry with the
+// retries configuration when it is set, and with the SDK defaults otherwise.
+func LoadAWSConfig(ctx context.Context, profile, region string, retries *Retries) (aws.Config, error) {
+	options := []func(*aws_config.LoadOptions) error{}
+	if retries != nil {
+		options = append(options, retries.loadOptions()...)
+	}
+	if profile != "" {
+		options = append(options, aws_config.WithSharedConfigProfile(profile))
+	}
+	if region != "" {
+		options = append(options, aws_config.WithRegion(region))
+	}
+	return aws_config.LoadDefaultConfig(ctx, options...)
+}
+
+// CheckCredentials retrieves the credentials of a configuration, so that an
+// expired IAM Identity Center (SSO) session is reported before the scan starts.
+func CheckCredentials(ctx context.Context, cfg aws.Config, profile string) error {
+	if cfg.Credentials == nil {
+		return fmt.Errorf("no credentials found for profile %s", profileName(profile))
+	}
+	_, err := cfg.Credentials.Retrieve(ctx)
+	var invalidToken *ssocreds.InvalidTokenError
+	if errors.As(err, &invalidToken) {
+		return fmt.Errorf("the SSO session of profile %s has expired, run `aws sso login --profile %s` and try again: %w", profileName(profile), profileName(profile), err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the credentials of profile %s: %w", profileName(profile), err)
+	}
+	return nil
+}
+
+// profileName returns the name of a profile, with "default" for the empty profile.
+func profileName(profile string) string {
+	if profile == "" {
+		return "default"
+	}
+	return profile
+}
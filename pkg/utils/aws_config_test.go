@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAWSConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(configFile, []byte("[profile acquisitions]\nregion = eu-west-1\n"), 0600))
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	cfg, err := LoadAWSConfig(context.TODO(), "acquisitions", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+
+	cfg, err = LoadAWSConfig(context.TODO(), "acquisitions", "us-east-2", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "us-east-2", cfg.Region)
+	assert.Nil(t, cfg.Retryer)
+
+	cfg, err = LoadAWSConfig(context.TODO(), "acquisitions", "", NewRetries(5))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cfg.Retryer().MaxAttempts())
+	assert.Len(t, cfg.APIOptions, 1)
+
+	_, err = LoadAWSConfig(context.TODO(), "missing", "", nil)
+	assert.Error(t, err)
+}
+
+func TestCheckCredentials(t *testing.T) {
+	expired := aws.Config{Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, &ssocreds.InvalidTokenError{Err: errors.New("token expired")}
+	})}
+	err := CheckCredentials(context.TODO(), expired, "prod-sso")
+	assert.ErrorContains(t, err, "aws sso login --profile prod-sso")
+
+	failing := aws.Config{Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, errors.New("test error")
+	})}
+	assert.ErrorContains(t, CheckCredentials(context.TODO(), failing, ""), "profile default")
+
+	valid := aws.Config{Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+	})}
+	assert.NoError(t, CheckCredentials(context.TODO(), valid, ""))
+	assert.Error(t, CheckCredentials(context.TODO(), aws.Config{}, ""))
+}
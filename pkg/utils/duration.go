@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseLookback parses a lookback window such as "30d", "12h" or "90m".
+// Day suffixes are accepted in addition to the units of time.ParseDuration.
+func ParseLookback(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.Atoi(days)
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid lookback %q", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid lookback %q", value)
+	}
+	return duration, nil
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+
+	"aws-resource-discovery/pkg/interfaces"
+)
+
+// JSONReport is the summary of a scan written to the JSON report.
+type JSONReport struct {
+	// GroupByTag is the key of the tag the by_tag totals are grouped by.
+	GroupByTag string `json:"group_by_tag,omitempty"`
+	// Totals holds the totals of every billing category, with their peak and
+	// per tag value totals when collected.
+	Totals interfaces.ResourceTotals `json:"totals"`
+	// OrganizationalUnits holds the totals of every organizational unit path,
+	// prefixed with the name of its target when more than one is scanned.
+	OrganizationalUnits map[string]*interfaces.ResourceTotals `json:"organizational_units,omitempty"`
+	// Accounts holds the totals of every scanned account.
+	Accounts map[string]*interfaces.ResourceTotals `json:"accounts,omitempty"`
+	// Targets holds the totals of every target when more than one is scanned.
+	Targets map[string]*interfaces.ResourceTotals `json:"targets,omitempty"`
+}
+
+// AddSubtotals adds the subtotals of a scan, such as the totals of its
+// accounts, to subtotals, with prefix in front of their names.
+func AddSubtotals(subtotals map[string]*interfaces.ResourceTotals, prefix string, scanned map[string]*interfaces.ResourceTotals) {
+	for name, totals := range scanned {
+		if subtotals[prefix+name] == nil {
+			subtotals[prefix+name] = &interfaces.ResourceTotals{}
+		}
+		subtotals[prefix+name].Add(*totals)
+	}
+}
+
+// WriteJSONReport writes the report as indented JSON to the file.
+func WriteJSONReport(filename string, report JSONReport) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(content, '\n'), 0644)
+}
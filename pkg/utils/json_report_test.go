@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aws-resource-discovery/pkg/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSONReport(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.json")
+
+	err := WriteJSONReport(filename, JSONReport{
+		GroupByTag: "cost-center",
+		Totals: interfaces.ResourceTotals{
+			VirtualMachines: 4,
+			ByTag: map[string]*interfaces.ResourceTotals{
+				"finance":                {VirtualMachines: 3},
+				interfaces.UntaggedGroup: {VirtualMachines: 1},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	var report map[string]any
+	assert.NoError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, "cost-center", report["group_by_tag"])
+	totals := report["totals"].(map[string]any)
+	assert.Equal(t, 4.0, totals["virtual_machines"])
+	assert.NotContains(t, totals, "peak")
+	assert.Equal(t, 3.0, totals["by_tag"].(map[string]any)["finance"].(map[string]any)["virtual_machines"])
+	assert.Equal(t, 1.0, totals["by_tag"].(map[string]any)["untagged"].(map[string]any)["virtual_machines"])
+	assert.NotContains(t, report, "targets")
+}
+
+func TestWriteJSONReportWithSubtotals(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.json")
+
+	err := WriteJSONReport(filename, JSONReport{
+		Totals:              interfaces.ResourceTotals{Buckets: 2},
+		OrganizationalUnits: map[string]*interfaces.ResourceTotals{"Root/Prod": {Buckets: 2}},
+		Accounts:            map[string]*interfaces.ResourceTotals{"123456789012": {Buckets: 2}},
+	})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	var report map[string]map[string]any
+	assert.NoError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, 2.0, report["organizational_units"]["Root/Prod"].(map[string]any)["storage_buckets"])
+	assert.Equal(t, 2.0, report["accounts"]["123456789012"].(map[string]any)["storage_buckets"])
+}
+
+func TestAddSubtotals(t *testing.T) {
+	subtotals := map[string]*interfaces.ResourceTotals{"prod:Root": {VirtualMachines: 1}}
+
+	AddSubtotals(subtotals, "prod:", map[string]*interfaces.ResourceTotals{"Root": {VirtualMachines: 2}, "Root/Sandbox": {Databases: 1}})
+	AddSubtotals(subtotals, "acquired:", map[string]*interfaces.ResourceTotals{"Root": {VirtualMachines: 4}})
+
+	assert.Equal(t, map[string]*interfaces.ResourceTotals{
+		"prod:Root":         {VirtualMachines: 3},
+		"prod:Root/Sandbox": {Databases: 1},
+		"acquired:Root":     {VirtualMachines: 4},
+	}, subtotals)
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"aws-resource-discovery/pkg/interfaces"
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// AWS partitions the scanner supports.
+const (
+	PartitionAws      = "aws"
+	PartitionGovCloud = "aws-us-gov"
+	PartitionChina    = "aws-cn"
+)
+
+// globalRegions holds the region of each partition where global resources,
+// such as S3 buckets, are counted.
+var globalRegions = map[string]string{
+	PartitionAws:      "us-east-1",
+	PartitionGovCloud: "us-gov-west-1",
+	PartitionChina:    "cn-north-1",
+}
+
+// PartitionForRegion returns the partition of a region.
+func PartitionForRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return PartitionGovCloud
+	case strings.HasPrefix(region, "cn-"):
+		return PartitionChina
+	default:
+		return PartitionAws
+	}
+}
+
+// DetectPartition returns the partition of the caller identity, or the
+// partition of the region when the caller identity cannot be read.
+func DetectPartition(ctx context.Context, client interfaces.STSClient, region string) string {
+	identity, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err == nil {
+		if parsed, err := arn.Parse(aws.ToString(identity.Arn)); err == nil {
+			return parsed.Partition
+		}
+	}
+	return PartitionForRegion(region)
+}
+
+// GlobalRegion returns the region of a partition where global resources are counted.
+func GlobalRegion(partition string) string {
+	if region, ok := globalRegions[partition]; ok {
+		return region
+	}
+	return globalRegions[PartitionAws]
+}
+
+// HasECRPublic reports whether ECR Public is available in a partition.
+func HasECRPublic(partition string) bool {
+	return partition == PartitionAws
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"aws-resource-discovery/pkg/mocks"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	assert.Equal(t, PartitionAws, PartitionForRegion("eu-west-1"))
+	assert.Equal(t, PartitionGovCloud, PartitionForRegion("us-gov-east-1"))
+	assert.Equal(t, PartitionChina, PartitionForRegion("cn-northwest-1"))
+}
+
+func TestGlobalRegion(t *testing.T) {
+	assert.Equal(t, "us-east-1", GlobalRegion(PartitionAws))
+	assert.Equal(t, "us-gov-west-1", GlobalRegion(PartitionGovCloud))
+	assert.Equal(t, "cn-north-1", GlobalRegion(PartitionChina))
+	assert.Equal(t, "us-east-1", GlobalRegion(""))
+	assert.True(t, HasECRPublic(PartitionAws))
+	assert.False(t, HasECRPublic(PartitionGovCloud))
+}
+
+func TestDetectPartition(t *testing.T) {
+	mockSTSClient := new(mocks.MockSTSClient)
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(&sts.GetCallerIdentityOutput{
+		Arn: aws.String("arn:aws-us-gov:iam::123456789012:user/scanner"),
+	}, nil).Once()
+	mockSTSClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(nil, errors.New("test error")).Once()
+
+	assert.Equal(t, PartitionGovCloud, DetectPartition(context.TODO(), mockSTSClient, "us-east-1"))
+	assert.Equal(t, PartitionChina, DetectPartition(context.TODO(), mockSTSClient, "cn-north-1"))
+}
@@ -2,6 +2,9 @@ package utils
 
 import (
     "aws-resource-discovery/pkg/interfaces"
+    "fmt"
+    "sort"
+    "strings"
     "github.com/fatih/color"
     "github.com/rodaine/table"
 )
@@ -9,6 +12,11 @@ import (
 func PrintTotals(totals interfaces.ResourceTotals) {
     headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
     columnFmt := color.New(color.FgYellow).SprintfFunc()
+    if totals.Peak != nil {
+        printTotalsWithPeak(totals, *totals.Peak)
+        printTotalsByTag(totals.ByTag)
+        return
+    }
     tbl := table.New("ResourceType", "Count").WithPadding(3)
     tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
     tbl.AddRow("Storage Buckets", totals.Buckets)
@@ -20,4 +28,312 @@ func PrintTotals(totals interfaces.ResourceTotals) {
     tbl.AddRow("Virtual Machines", totals.VirtualMachines)
     tbl.AddRow("Container Registry Images", totals.ContainerRegistryImages)
     tbl.Print()
-}
\ No newline at end of file
+    printTotalsByTag(totals.ByTag)
+}
+
+// printTotalsWithPeak prints the current totals next to the peak totals observed over the lookback window.
+func printTotalsWithPeak(current, peak interfaces.ResourceTotals) {
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    tbl := table.New("ResourceType", "Current", "Peak").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    tbl.AddRow("Storage Buckets", current.Buckets, peak.Buckets)
+    tbl.AddRow("Container Hosts", current.ContainerHosts, peak.ContainerHosts)
+    tbl.AddRow("Databases", current.Databases, peak.Databases)
+    tbl.AddRow("Non-OS Disks", current.NonOsDisks, peak.NonOsDisks)
+    tbl.AddRow("Serverless Containers", current.ServerlessContainers, peak.ServerlessContainers)
+    tbl.AddRow("Serverless Functions", current.ServerlessFunctions, peak.ServerlessFunctions)
+    tbl.AddRow("Virtual Machines", current.VirtualMachines, peak.VirtualMachines)
+    tbl.AddRow("Container Registry Images", current.ContainerRegistryImages, peak.ContainerRegistryImages)
+    tbl.Print()
+}
+
+// PrintDiscrepancies prints the resource types whose native and CloudControl listings disagree.
+func PrintDiscrepancies(discrepancies []interfaces.Discrepancy) {
+    if len(discrepancies) == 0 {
+        fmt.Println("\nNative and CloudControl counts agree.")
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    fmt.Printf("\nNative and CloudControl counts disagree for %d resource types:\n\n", len(discrepancies))
+    tbl := table.New("Account", "Region", "ResourceType", "Native", "CloudControl", "Difference", "Only Native", "Only CloudControl").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, discrepancy := range discrepancies {
+        tbl.AddRow(
+            discrepancy.AccountId,
+            discrepancy.Region,
+            discrepancy.ResourceType,
+            discrepancy.NativeCount,
+            discrepancy.CloudControlCount,
+            fmt.Sprintf("%.1f%%", discrepancy.Difference()),
+            strings.Join(discrepancy.OnlyNative, " "),
+            strings.Join(discrepancy.OnlyCloudControl, " "),
+        )
+    }
+    tbl.Print()
+}
+
+// PrintFiltered prints the number of resources each tag filter removed from the
+// totals, and the resources of every type the filters could not be applied to.
+func PrintFiltered(filtered, unfiltered map[string]int) {
+    printUnfiltered(unfiltered)
+    if len(filtered) == 0 {
+        fmt.Println("\nNo resources were removed by the tag filters.")
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    filters := make([]string, 0, len(filtered))
+    for filter := range filtered {
+        filters = append(filters, filter)
+    }
+    sort.Strings(filters)
+    fmt.Println()
+    tbl := table.New("Filter", "Removed").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, filter := range filters {
+        tbl.AddRow(filter, filtered[filter])
+    }
+    tbl.Print()
+}
+
+// printUnfiltered prints the resources of every type left in the totals
+// because their tags could not be read.
+func printUnfiltered(unfiltered map[string]int) {
+    if len(unfiltered) == 0 {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    resourceTypes := make([]string, 0, len(unfiltered))
+    for resourceType := range unfiltered {
+        resourceTypes = append(resourceTypes, resourceType)
+    }
+    sort.Strings(resourceTypes)
+    fmt.Println("\nThe tags of these resources could not be read, so they were counted without filtering:")
+    fmt.Println()
+    tbl := table.New("ResourceType", "Unfiltered").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, resourceType := range resourceTypes {
+        tbl.AddRow(resourceType, unfiltered[resourceType])
+    }
+    tbl.Print()
+}
+
+// printTotalsByTag prints the non-zero totals of every value of the grouping tag,
+// followed by the untagged resources.
+func printTotalsByTag(byTag map[string]*interfaces.ResourceTotals) {
+    if byTag == nil {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    groups := make([]string, 0, len(byTag))
+    for group := range byTag {
+        if group != interfaces.UntaggedGroup {
+            groups = append(groups, group)
+        }
+    }
+    sort.Strings(groups)
+    if _, ok := byTag[interfaces.UntaggedGroup]; ok {
+        groups = append(groups, interfaces.UntaggedGroup)
+    }
+
+    fmt.Println()
+    tbl := table.New("TagValue", "BillingCategory", "Count").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, group := range groups {
+        for _, category := range byTag[group].Categories() {
+            if category.Count > 0 {
+                tbl.AddRow(group, category.Name, category.Count)
+            }
+        }
+    }
+    tbl.Print()
+}
+
+// PrintSubtotals prints one row of totals per name, such as an account or an
+// organizational unit, in the given order.
+func PrintSubtotals(title string, names []string, subtotals map[string]*interfaces.ResourceTotals) {
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    fmt.Println()
+    tbl := table.New(title, "Buckets", "Container Hosts", "Databases", "Non-OS Disks", "Serverless Containers", "Serverless Functions", "VMs", "Registry Images").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, name := range names {
+        totals := subtotals[name]
+        tbl.AddRow(
+            name,
+            totals.Buckets,
+            totals.ContainerHosts,
+            totals.Databases,
+            totals.NonOsDisks,
+            totals.ServerlessContainers,
+            totals.ServerlessFunctions,
+            totals.VirtualMachines,
+            totals.ContainerRegistryImages,
+        )
+    }
+    tbl.Print()
+}
+
+// PrintSkippedAccounts prints the accounts of the organization that were not
+// scanned, with the reason and the filter, status or error behind it.
+func PrintSkippedAccounts(skipped []interfaces.SkippedAccount) {
+    if len(skipped) == 0 {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    fmt.Printf("\nSkipped %d accounts:\n\n", len(skipped))
+    tbl := table.New("Account", "Reason", "Detail").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, account := range skipped {
+        tbl.AddRow(account.AccountId, account.Reason, account.Detail)
+    }
+    tbl.Print()
+}
+
+// PrintFailedTargets prints the targets that could not be scanned with the
+// error of each.
+func PrintFailedTargets(failed map[string]error) {
+    if len(failed) == 0 {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    names := make([]string, 0, len(failed))
+    for name := range failed {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    fmt.Printf("\nFailed %d targets:\n\n", len(failed))
+    tbl := table.New("Target", "Error").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, name := range names {
+        tbl.AddRow(name, failed[name])
+    }
+    tbl.Print()
+}
+
+// PrintAccountRoles prints the role assumed in each scanned account.
+func PrintAccountRoles(roles map[string]string) {
+    if len(roles) == 0 {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    accounts := make([]string, 0, len(roles))
+    for account := range roles {
+        accounts = append(accounts, account)
+    }
+    sort.Strings(accounts)
+    fmt.Println()
+    tbl := table.New("Account", "Role").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, account := range accounts {
+        tbl.AddRow(account, roles[account])
+    }
+    tbl.Print()
+}
+
+// PrintRegionStatuses prints the regions that were not scanned in every
+// account, because they are disabled in it or blocked by a service control
+// policy.
+func PrintRegionStatuses(statuses map[string]map[string]string) {
+    unscanned := map[string]map[string][]string{}
+    for account, regions := range statuses {
+        for region, status := range regions {
+            if status == interfaces.RegionEnabled {
+                continue
+            }
+            if unscanned[account] == nil {
+                unscanned[account] = map[string][]string{}
+            }
+            unscanned[account][status] = append(unscanned[account][status], region)
+        }
+    }
+    if len(unscanned) == 0 {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    accounts := make([]string, 0, len(unscanned))
+    for account := range unscanned {
+        accounts = append(accounts, account)
+    }
+    sort.Strings(accounts)
+    fmt.Println()
+    tbl := table.New("Account", "Status", "Regions").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, account := range accounts {
+        for _, status := range []string{interfaces.RegionBlocked, interfaces.RegionDisabled} {
+            regions := unscanned[account][status]
+            if len(regions) == 0 {
+                continue
+            }
+            sort.Strings(regions)
+            tbl.AddRow(account, status, strings.Join(regions, ", "))
+        }
+    }
+    tbl.Print()
+}
+
+// PrintRetryStats prints the requests, throttles, retries and failures of
+// every AWS service that throttled or retried requests.
+func PrintRetryStats(stats map[string]RetryStat) {
+    if len(stats) == 0 {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    fmt.Println()
+    tbl := table.New("Service", "Requests", "Throttles", "Retries", "Failures").WithPadding(3)
+    tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+    for _, service := range sortedServices(stats) {
+        stat := stats[service]
+        tbl.AddRow(service, stat.Requests, stat.Throttles, stat.Retries, stat.Failures)
+    }
+    tbl.Print()
+}
+
+// PrintPermissionSuggestions prints the permissions needed by the counters that
+// failed, with the accounts they failed in, followed by the actions the policy
+// simulation found denied and the type of policy that denied them.
+func PrintPermissionSuggestions(suggestions map[string]*interfaces.PermissionSuggestion, denied []interfaces.DeniedAction) {
+    if len(suggestions) == 0 && len(denied) == 0 {
+        return
+    }
+    headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+    columnFmt := color.New(color.FgYellow).SprintfFunc()
+    resourceTypes := make([]string, 0, len(suggestions))
+    for resourceType := range suggestions {
+        resourceTypes = append(resourceTypes, resourceType)
+    }
+    sort.Strings(resourceTypes)
+
+    fmt.Println("\nPermission suggestions:")
+    if len(resourceTypes) > 0 {
+        for _, resourceType := range resourceTypes {
+            fmt.Print(suggestions[resourceType].Message)
+        }
+        fmt.Println()
+        tbl := table.New("ResourceType", "Failed in accounts").WithPadding(3)
+        tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+        for _, resourceType := range resourceTypes {
+            tbl.AddRow(resourceType, strings.Join(suggestions[resourceType].Accounts, ", "))
+        }
+        tbl.Print()
+    }
+
+    if len(denied) > 0 {
+        fmt.Println()
+        tbl := table.New("Account", "Action", "Denied by", "Decision").WithPadding(3)
+        tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+        for _, action := range denied {
+            tbl.AddRow(action.AccountId, action.Action, action.DeniedBy, action.Decision)
+        }
+        tbl.Print()
+    }
+}
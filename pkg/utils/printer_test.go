@@ -3,6 +3,8 @@ package utils
 import (
 	"aws-resource-discovery/pkg/interfaces"
 	"bytes"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/rodaine/table"
@@ -46,3 +48,168 @@ func TestPrintTotals(t *testing.T) {
 	assert.Contains(t, output, "35")
 	assert.Contains(t, output, "40")
 }
+
+func TestPrintTotalsWithPeak(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	totals := interfaces.ResourceTotals{
+		VirtualMachines: 35,
+		Peak:            &interfaces.ResourceTotals{VirtualMachines: 105},
+	}
+	PrintTotals(totals)
+	output := buf.String()
+
+	assert.Contains(t, output, "Current")
+	assert.Contains(t, output, "Peak")
+	assert.Contains(t, output, "35")
+	assert.Contains(t, output, "105")
+}
+
+func TestPrintDiscrepancies(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintDiscrepancies([]interfaces.Discrepancy{{
+		AccountId:         "123456789012",
+		Region:            "us-east-1",
+		ResourceType:      "AWS::EC2::Instance",
+		NativeCount:       4,
+		CloudControlCount: 5,
+		Mismatched:        1,
+		OnlyCloudControl:  []string{"i-0123"},
+	}})
+	output := buf.String()
+
+	assert.Contains(t, output, "AWS::EC2::Instance")
+	assert.Contains(t, output, "20.0%")
+	assert.Contains(t, output, "i-0123")
+}
+
+func TestPrintFiltered(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintFiltered(map[string]int{"EXCLUDE_TAGS env=sandbox": 12, "INCLUDE_TAGS": 3}, map[string]int{"AWS::ECR::PublicRepository": 7})
+	output := buf.String()
+
+	assert.Contains(t, output, "EXCLUDE_TAGS env=sandbox")
+	assert.Contains(t, output, "12")
+	assert.Contains(t, output, "INCLUDE_TAGS")
+	assert.Contains(t, output, "AWS::ECR::PublicRepository")
+	assert.Contains(t, output, "7")
+}
+
+func TestPrintTotalsByTag(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	totals := interfaces.ResourceTotals{
+		VirtualMachines: 7,
+		ByTag: map[string]*interfaces.ResourceTotals{
+			interfaces.UntaggedGroup: {VirtualMachines: 3},
+			"finance":                {VirtualMachines: 4},
+		},
+	}
+	PrintTotals(totals)
+	output := buf.String()
+
+	assert.Contains(t, output, "TagValue")
+	assert.Contains(t, output, "finance")
+	assert.Contains(t, output, "untagged")
+	assert.Less(t, strings.Index(output, "finance"), strings.Index(output, "untagged"))
+	assert.NotContains(t, output[strings.Index(output, "TagValue"):], "Storage Buckets")
+}
+
+func TestPrintSubtotals(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintSubtotals("OrganizationalUnit", []string{"Root/Security", "Root"}, map[string]*interfaces.ResourceTotals{
+		"Root":          {VirtualMachines: 3},
+		"Root/Security": {Buckets: 2, VirtualMachines: 4},
+	})
+	output := buf.String()
+
+	assert.Contains(t, output, "OrganizationalUnit")
+	assert.Contains(t, output, "Root/Security")
+	assert.Less(t, strings.Index(output, "Root/Security"), strings.Index(output, "Root "))
+}
+
+func TestPrintSkippedAccounts(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintSkippedAccounts([]interfaces.SkippedAccount{
+		{AccountId: "111111111111", Reason: interfaces.SkipReasonSuspended, Detail: "SUSPENDED"},
+		{AccountId: "222222222222", Reason: interfaces.SkipReasonLookupFailed, Detail: "TooManyRequestsException"},
+	})
+	output := buf.String()
+
+	assert.Contains(t, output, "111111111111")
+	assert.Contains(t, output, "lookup-failed")
+	assert.Contains(t, output, "TooManyRequestsException")
+}
+
+func TestPrintFailedTargets(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintFailedTargets(map[string]error{
+		"acquisitions": errors.New("invalid AWS credentials: expired token"),
+	})
+	output := buf.String()
+
+	assert.Contains(t, output, "acquisitions")
+	assert.Contains(t, output, "expired token")
+}
+
+func TestPrintAccountRoles(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintAccountRoles(map[string]string{
+		"222222222222": "SecurityAuditRole",
+		"111111111111": "red-canary-resource-discovery-role",
+	})
+	output := buf.String()
+
+	assert.Contains(t, output, "SecurityAuditRole")
+	assert.Less(t, strings.Index(output, "111111111111"), strings.Index(output, "222222222222"))
+}
+
+func TestPrintRegionStatuses(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintRegionStatuses(map[string]map[string]string{
+		"111111111111": {"us-east-1": interfaces.RegionEnabled, "me-south-1": interfaces.RegionDisabled, "af-south-1": interfaces.RegionDisabled, "sa-east-1": interfaces.RegionBlocked},
+		"222222222222": {"us-east-1": interfaces.RegionEnabled},
+	})
+	output := buf.String()
+
+	assert.Contains(t, output, "af-south-1, me-south-1")
+	assert.Contains(t, output, "blocked")
+	assert.Contains(t, output, "sa-east-1")
+	assert.NotContains(t, output, "222222222222")
+	assert.NotContains(t, output, "us-east-1")
+}
+
+func TestPrintRetryStats(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintRetryStats(map[string]RetryStat{
+		"ECS":          {Requests: 40, Throttles: 3, Retries: 3},
+		"CloudControl": {Requests: 120, Throttles: 12, Retries: 10, Failures: 1},
+	})
+	output := buf.String()
+
+	assert.Contains(t, output, "Throttles")
+	assert.Less(t, strings.Index(output, "CloudControl"), strings.Index(output, "ECS"))
+}
+
+func TestPrintPermissionSuggestions(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	table.DefaultWriter = buf
+	PrintPermissionSuggestions(map[string]*interfaces.PermissionSuggestion{
+		"AWS::ECS::Cluster": {Message: "\nTo scan ECS clusters, the provided credentials must have the following permissions:\n- ecs:ListClusters\n", Accounts: []string{"111111111111", "222222222222"}},
+	}, []interfaces.DeniedAction{
+		{AccountId: "111111111111", Action: "ecs:ListClusters", DeniedBy: interfaces.DeniedBySCP, Decision: "explicitDeny"},
+	})
+	output := buf.String()
+
+	assert.Contains(t, output, "111111111111, 222222222222")
+	assert.Contains(t, output, "ecs:ListClusters")
+	assert.Contains(t, output, "scp")
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	aws_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultMaxAttempts is the number of attempts of every AWS request when
+// MAX_ATTEMPTS is not set.
+const DefaultMaxAttempts = 10
+
+// throttles recognizes the throttling errors of the AWS services, such as
+// ThrottlingException and TooManyRequestsException.
+var throttles = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+// RetryStat holds the throttling and retry statistics of a service.
+type RetryStat struct {
+	// Requests counts the requests sent to the service.
+	Requests int
+	// Throttles counts the attempts that failed with a throttling error.
+	Throttles int
+	// Retries counts the attempts made after a failed attempt.
+	Retries int
+	// Failures counts the requests that were still throttled after the last
+	// attempt.
+	Failures int
+}
+
+// Retries is the retry configuration shared by every AWS client. Every client
+// gets its own adaptive retryer, so that each service of an account and region
+// has its own token bucket, and the attempts of every request are recorded by
+// service.
+type Retries struct {
+	MaxAttempts int
+
+	mu       sync.Mutex
+	services map[string]*RetryStat
+}
+
+// NewRetries creates the retry configuration of the clients, with the
+// default number of attempts when maxAttempts is not positive.
+func NewRetries(maxAttempts int) *Retries {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	return &Retries{MaxAttempts: maxAttempts, services: map[string]*RetryStat{}}
+}
+
+// loadOptions returns the options that set the retryer of a configuration
+// and record the attempts of its requests.
+func (r *Retries) loadOptions() []func(*aws_config.LoadOptions) error {
+	return []func(*aws_config.LoadOptions) error{
+		aws_config.WithRetryer(r.newRetryer),
+		aws_config.WithAPIOptions([]func(*middleware.Stack) error{r.addMiddleware}),
+	}
+}
+
+// newRetryer creates the adaptive retryer of a client.
+func (r *Retries) newRetryer() aws.Retryer {
+	return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+		o.StandardOptions = append(o.StandardOptions, func(so *retry.StandardOptions) {
+			so.MaxAttempts = r.MaxAttempts
+		})
+	})
+}
+
+// addMiddleware records the attempts of every request once the retries are
+// done. It runs after the service metadata is registered, so that the service
+// of the request is known.
+func (r *Retries) addMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RetryStats", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		out, metadata, err := next.HandleInitialize(ctx, in)
+		results, _ := retry.GetAttemptResults(metadata)
+		r.record(awsmiddleware.GetServiceID(ctx), results.Results, err)
+		return out, metadata, err
+	}), middleware.After)
+}
+
+// record adds the attempts of a request to the statistics of its service.
+func (r *Retries) record(service string, attempts []retry.AttemptResult, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stat, ok := r.services[service]
+	if !ok {
+		stat = &RetryStat{}
+		r.services[service] = stat
+	}
+	stat.Requests++
+	if len(attempts) > 1 {
+		stat.Retries += len(attempts) - 1
+	}
+	for _, attempt := range attempts {
+		if attempt.Err != nil && throttles.IsErrorThrottle(attempt.Err) == aws.TrueTernary {
+			stat.Throttles++
+		}
+	}
+	if err != nil && throttles.IsErrorThrottle(err) == aws.TrueTernary {
+		stat.Failures++
+	}
+}
+
+// Stats returns the statistics of the services that were throttled or retried.
+func (r *Retries) Stats() map[string]RetryStat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := map[string]RetryStat{}
+	for service, stat := range r.services {
+		if stat.Throttles > 0 || stat.Retries > 0 {
+			stats[service] = *stat
+		}
+	}
+	return stats
+}
+
+// sortedServices returns the services of the statistics in alphabetical order.
+func sortedServices(stats map[string]RetryStat) []string {
+	services := make([]string, 0, len(stats))
+	for service := range stats {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetries(t *testing.T) {
+	assert.Equal(t, DefaultMaxAttempts, NewRetries(0).MaxAttempts)
+	retries := NewRetries(4)
+	assert.Equal(t, 4, retries.MaxAttempts)
+	assert.Equal(t, 4, retries.newRetryer().MaxAttempts())
+	assert.IsType(t, &retry.AdaptiveMode{}, retries.newRetryer())
+}
+
+func TestRetries_record(t *testing.T) {
+	retries := NewRetries(3)
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException"}
+	tooMany := &smithy.GenericAPIError{Code: "TooManyRequestsException"}
+
+	// Throttled once, then succeeded
+	retries.record("CloudControl", []retry.AttemptResult{{Err: throttled, Retryable: true, Retried: true}, {}}, nil)
+	// Throttled on every attempt
+	retries.record("ECS", []retry.AttemptResult{{Err: tooMany, Retried: true}, {Err: tooMany, Retried: true}, {Err: tooMany}}, tooMany)
+	// Failed without throttling or retrying
+	retries.record("Organizations", []retry.AttemptResult{{Err: errors.New("access denied")}}, errors.New("access denied"))
+
+	stats := retries.Stats()
+	assert.Equal(t, RetryStat{Requests: 1, Throttles: 1, Retries: 1}, stats["CloudControl"])
+	assert.Equal(t, RetryStat{Requests: 1, Throttles: 3, Retries: 2, Failures: 1}, stats["ECS"])
+	assert.NotContains(t, stats, "Organizations")
+}
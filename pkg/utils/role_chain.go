@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"aws-resource-discovery/pkg/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// ParseRoleChain parses a comma-separated list of role ARNs, each optionally
+// followed by | and the external ID to assume it with, such as
+// "arn:aws:iam::111111111111:role/hub|external-id,arn:aws:iam::222222222222:role/scanner".
+func ParseRoleChain(value string) ([]config.ChainedRole, error) {
+	if value == "" {
+		return nil, nil
+	}
+	chain := []config.ChainedRole{}
+	for _, hop := range strings.Split(value, ",") {
+		roleArn, externalId, _ := strings.Cut(strings.TrimSpace(hop), "|")
+		if _, err := arn.Parse(roleArn); err != nil {
+			return nil, fmt.Errorf("invalid role ARN %q: %w", roleArn, err)
+		}
+		chain = append(chain, config.ChainedRole{RoleArn: roleArn, ExternalId: externalId})
+	}
+	return chain, nil
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+
+	"aws-resource-discovery/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRoleChain(t *testing.T) {
+	chain, err := ParseRoleChain("arn:aws:iam::111111111111:role/hub|external-id, arn:aws:iam::222222222222:role/scanner")
+	assert.NoError(t, err)
+	assert.Equal(t, []config.ChainedRole{
+		{RoleArn: "arn:aws:iam::111111111111:role/hub", ExternalId: "external-id"},
+		{RoleArn: "arn:aws:iam::222222222222:role/scanner"},
+	}, chain)
+
+	chain, err = ParseRoleChain("")
+	assert.NoError(t, err)
+	assert.Nil(t, chain)
+
+	_, err = ParseRoleChain("hub|external-id")
+	assert.ErrorContains(t, err, `invalid role ARN "hub"`)
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadRoleMap reads a JSON file mapping account IDs to the name of the role to
+// assume in them, or to a list of role names to try in order, such as
+// {"123456789012": "SecurityAuditRole", "210987654321": ["RoleA", "RoleB"]}.
+func LoadRoleMap(path string) (map[string][]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("invalid role map %s: %w", path, err)
+	}
+
+	roleMap := make(map[string][]string, len(entries))
+	for accountId, entry := range entries {
+		var roleName string
+		if err := json.Unmarshal(entry, &roleName); err == nil {
+			roleMap[accountId] = []string{roleName}
+			continue
+		}
+		var roleNames []string
+		if err := json.Unmarshal(entry, &roleNames); err != nil {
+			return nil, fmt.Errorf("invalid roles for account %s in %s: %w", accountId, path, err)
+		}
+		roleMap[accountId] = roleNames
+	}
+	return roleMap, nil
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRoleMap(t *testing.T) {
+	filename := "test_role_map.json"
+	defer os.Remove(filename)
+	os.WriteFile(filename, []byte(`{"123456789012": "SecurityAuditRole", "210987654321": ["RoleA", "RoleB"]}`), 0600)
+
+	roleMap, err := LoadRoleMap(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"123456789012": {"SecurityAuditRole"},
+		"210987654321": {"RoleA", "RoleB"},
+	}, roleMap)
+
+	os.WriteFile(filename, []byte(`{"123456789012": 1}`), 0600)
+	_, err = LoadRoleMap(filename)
+	assert.ErrorContains(t, err, "invalid roles for account 123456789012")
+
+	_, err = LoadRoleMap("missing.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"aws-resource-discovery/pkg/config"
+)
+
+// ParseTagFilters parses a comma-separated list of tag filters such as
+// "env=sandbox,rc-exclude". A filter without a value matches any value of the key.
+func ParseTagFilters(value string) ([]config.TagFilter, error) {
+	if value == "" {
+		return nil, nil
+	}
+	filters := []config.TagFilter{}
+	for _, filter := range strings.Split(value, ",") {
+		key, tagValue, _ := strings.Cut(strings.TrimSpace(filter), "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid tag filter %q", filter)
+		}
+		filters = append(filters, config.TagFilter{Key: key, Value: tagValue})
+	}
+	return filters, nil
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"aws-resource-discovery/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTagFilters(t *testing.T) {
+	filters, err := ParseTagFilters("env=sandbox, rc-exclude")
+	assert.NoError(t, err)
+	assert.Equal(t, []config.TagFilter{{Key: "env", Value: "sandbox"}, {Key: "rc-exclude"}}, filters)
+	assert.Equal(t, "env=sandbox", filters[0].String())
+	assert.Equal(t, "rc-exclude", filters[1].String())
+
+	filters, err = ParseTagFilters("")
+	assert.NoError(t, err)
+	assert.Nil(t, filters)
+
+	_, err = ParseTagFilters("env=sandbox,=true")
+	assert.Error(t, err)
+}